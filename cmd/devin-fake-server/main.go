@@ -0,0 +1,67 @@
+// Command devin-fake-server is a local stand-in for the Devin API's knowledge
+// endpoints, for rehearsing terraform plan and apply and for integration
+// tests without network access. Point the provider at it with
+//
+//	provider "devin" {
+//	  endpoint = "http://127.0.0.1:8080/v1"
+//	}
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"time"
+)
+
+func main() {
+	var (
+		addr         string
+		prefix       string
+		statePath    string
+		apiKey       string
+		latency      time.Duration
+		throttleRate float64
+		errorRate    float64
+		retryAfter   time.Duration
+	)
+
+	flag.StringVar(&addr, "addr", "127.0.0.1:8080", "Address to listen on")
+	flag.StringVar(&prefix, "prefix", "/v1", "Path prefix of the API routes")
+	flag.StringVar(&statePath, "state", "", "JSON file to load the state from and persist it to; empty keeps it in memory")
+	flag.StringVar(&apiKey, "api-key", os.Getenv("DEVIN_API_KEY"), "API key clients must send as a Bearer token (default $DEVIN_API_KEY)")
+	flag.DurationVar(&latency, "latency", 0, "Delay added to every request")
+	flag.Float64Var(&throttleRate, "throttle-rate", 0, "Fraction of requests (0-1) answered with 429 Too Many Requests")
+	flag.Float64Var(&errorRate, "error-rate", 0, "Fraction of requests (0-1) answered with 503 Service Unavailable")
+	flag.DurationVar(&retryAfter, "retry-after", time.Second, "Retry-After sent with injected 429 responses")
+	flag.Parse()
+
+	logger := log.New(os.Stderr, "devin-fake-server: ", log.LstdFlags)
+
+	if apiKey == "" {
+		logger.Fatal("an API key is required: set -api-key or DEVIN_API_KEY")
+	}
+
+	backend, err := loadState(statePath)
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	s := &server{
+		backend:      backend,
+		apiKey:       apiKey,
+		statePath:    statePath,
+		latency:      latency,
+		throttleRate: throttleRate,
+		errorRate:    errorRate,
+		retryAfter:   retryAfter,
+		random:       defaultRandom,
+		logger:       logger,
+	}
+
+	logger.Printf("serving the Devin API on http://%s%s", addr, prefix)
+	if err := http.ListenAndServe(addr, s.handler(prefix)); err != nil {
+		logger.Fatal(err)
+	}
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hirosi1900day/terraform-provider-devin-knowledge/internal/provider"
+)
+
+// server serves the knowledge endpoints of the Devin API from a store
+type server struct {
+	backend *store
+	// Bearer token every request must carry
+	apiKey string
+	// JSON file the state is persisted to after every change; empty keeps
+	// the state in memory only
+	statePath string
+
+	// Fault injection: delay added to every request, and the probabilities
+	// of answering 429 or 503 instead of serving the request
+	latency      time.Duration
+	throttleRate float64
+	errorRate    float64
+	retryAfter   time.Duration
+	random       func() float64
+
+	// Serializes mutations so the state file is written in order
+	mu     sync.Mutex
+	logger *log.Logger
+}
+
+// loadState reads a state file written by the server. A missing file
+// yields an empty store.
+func loadState(path string) (*store, error) {
+	if path == "" {
+		return newStore(&provider.ListKnowledgeResponse{}), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return newStore(&provider.ListKnowledgeResponse{}), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+
+	var state provider.ListKnowledgeResponse
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to decode state file %s: %w", path, err)
+	}
+	return newStore(&state), nil
+}
+
+// saveState writes the current state to the state file, if any
+func (s *server) saveState() error {
+	if s.statePath == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(s.backend.list(), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file and rename it, so a crash never leaves a
+	// truncated state file behind
+	tmp, err := os.CreateTemp(filepath.Dir(s.statePath), filepath.Base(s.statePath)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.statePath)
+}
+
+// handler returns the HTTP handler serving the API under prefix (e.g. "/v1")
+func (s *server) handler(prefix string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+prefix+"/knowledge", s.listKnowledge)
+	mux.HandleFunc("POST "+prefix+"/knowledge", s.createKnowledge)
+	mux.HandleFunc("PUT "+prefix+"/knowledge/{id}", s.updateKnowledge)
+	mux.HandleFunc("DELETE "+prefix+"/knowledge/{id}", s.deleteKnowledge)
+	return s.intercept(mux)
+}
+
+// intercept applies latency, authentication and fault injection before
+// passing the request to next
+func (s *server) intercept(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		defer func() {
+			if s.logger != nil {
+				s.logger.Printf("%s %s %d", r.Method, r.URL.RequestURI(), rec.status)
+			}
+		}()
+
+		if s.latency > 0 {
+			select {
+			case <-time.After(s.latency):
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		if r.Header.Get("Authorization") != "Bearer "+s.apiKey {
+			writeError(rec, http.StatusUnauthorized, "authentication_error", "invalid API key")
+			return
+		}
+
+		switch {
+		case s.throttleRate > 0 && s.random() < s.throttleRate:
+			rec.Header().Set("Retry-After", strconv.Itoa(int(s.retryAfter.Seconds())))
+			writeError(rec, http.StatusTooManyRequests, "rate_limit_error", "too many requests (injected)")
+			return
+		case s.errorRate > 0 && s.random() < s.errorRate:
+			writeError(rec, http.StatusServiceUnavailable, "server_error", "service unavailable (injected)")
+			return
+		}
+
+		next.ServeHTTP(rec, r)
+	})
+}
+
+// listKnowledge serves GET /knowledge. A limit query parameter paginates
+// the list with next_cursor; responses carry an ETag and honor If-None-Match.
+func (s *server) listKnowledge(w http.ResponseWriter, r *http.Request) {
+	list := s.backend.list()
+
+	page := map[string]interface{}{
+		"knowledge": list.Knowledge,
+		"folders":   list.Folders,
+	}
+	if limit, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && limit > 0 {
+		start := 0
+		if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+			if start, err = strconv.Atoi(cursor); err != nil || start < 0 || start > len(list.Knowledge) {
+				writeError(w, http.StatusBadRequest, "invalid_request_error", "invalid cursor")
+				return
+			}
+		}
+		end := min(start+limit, len(list.Knowledge))
+		page["knowledge"] = list.Knowledge[start:end]
+		if end < len(list.Knowledge) {
+			page["next_cursor"] = strconv.Itoa(end)
+		}
+	}
+
+	body, err := json.Marshal(page)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
+
+// createKnowledge serves POST /knowledge
+func (s *server) createKnowledge(w http.ResponseWriter, r *http.Request) {
+	var req provider.CreateKnowledgeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", "invalid JSON body: "+err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	knowledge, err := s.backend.create(req)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+	if err := s.saveState(); err != nil {
+		writeBackendError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, knowledge)
+}
+
+// updateKnowledge serves PUT /knowledge/{id}
+func (s *server) updateKnowledge(w http.ResponseWriter, r *http.Request) {
+	var req provider.UpdateKnowledgeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", "invalid JSON body: "+err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	knowledge, err := s.backend.update(r.PathValue("id"), req)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+	if err := s.saveState(); err != nil {
+		writeBackendError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, knowledge)
+}
+
+// deleteKnowledge serves DELETE /knowledge/{id}
+func (s *server) deleteKnowledge(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.backend.delete(r.PathValue("id")); err != nil {
+		writeBackendError(w, err)
+		return
+	}
+	if err := s.saveState(); err != nil {
+		writeBackendError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeJSON writes v as a JSON response
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error in the Devin API's ErrorResponse format
+func writeError(w http.ResponseWriter, status int, errType, message string) {
+	var resp provider.ErrorResponse
+	resp.Error.Type = errType
+	resp.Error.Message = message
+	writeJSON(w, status, resp)
+}
+
+// writeBackendError maps an error from the store to an HTTP response
+func writeBackendError(w http.ResponseWriter, err error) {
+	var apiErr *provider.APIError
+	switch {
+	case errors.As(err, &apiErr):
+		writeError(w, apiErr.StatusCode, apiErr.Type, apiErr.Message)
+	default:
+		writeError(w, http.StatusInternalServerError, "server_error", err.Error())
+	}
+}
+
+// statusRecorder remembers the status code written for request logging
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader records the status code
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// defaultRandom returns a random number in [0, 1)
+func defaultRandom() float64 {
+	return rand.Float64()
+}
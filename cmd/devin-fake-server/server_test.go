@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/hirosi1900day/terraform-provider-devin-knowledge/internal/provider"
+)
+
+const testAPIKey = "secret"
+
+// newTestServer starts the fake server and returns its API endpoint
+func newTestServer(t *testing.T, s *server) string {
+	t.Helper()
+	if s.backend == nil {
+		backend, err := loadState(s.statePath)
+		if err != nil {
+			t.Fatalf("loadState() error = %v", err)
+		}
+		s.backend = backend
+	}
+	s.apiKey = testAPIKey
+	s.random = defaultRandom
+
+	ts := httptest.NewServer(s.handler("/v1"))
+	t.Cleanup(ts.Close)
+	return ts.URL + "/v1"
+}
+
+// newProviderClient returns a provider client for the endpoint
+func newProviderClient(t *testing.T, apiKey, endpoint string) *provider.DevinClient {
+	t.Helper()
+	client, err := provider.NewClient(apiKey, endpoint)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	client.MaxRetries = 0
+	return client
+}
+
+func TestServer_ProviderLifecycle(t *testing.T) {
+	ctx := context.Background()
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	client := newProviderClient(t, testAPIKey, newTestServer(t, &server{statePath: statePath}))
+
+	created, err := client.CreateKnowledge(ctx, "Knowledge", "body", "trigger", "")
+	if err != nil {
+		t.Fatalf("CreateKnowledge() error = %v", err)
+	}
+	if _, err := client.UpdateKnowledge(ctx, created.ID, "Renamed", "body", "trigger", ""); err != nil {
+		t.Fatalf("UpdateKnowledge() error = %v", err)
+	}
+
+	// A second server started from the state file sees the change
+	other := newProviderClient(t, testAPIKey, newTestServer(t, &server{statePath: statePath}))
+	knowledge, err := other.GetKnowledge(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("GetKnowledge() error = %v", err)
+	}
+	if knowledge.Name != "Renamed" {
+		t.Errorf("GetKnowledge() Name = %s, want Renamed", knowledge.Name)
+	}
+
+	if err := client.DeleteKnowledge(ctx, created.ID); err != nil {
+		t.Fatalf("DeleteKnowledge() error = %v", err)
+	}
+	if err := client.DeleteKnowledge(ctx, created.ID); !errors.Is(err, provider.ErrNotFound) {
+		t.Errorf("DeleteKnowledge() twice error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestServer_Pagination(t *testing.T) {
+	ctx := context.Background()
+	endpoint := newTestServer(t, &server{})
+	client := newProviderClient(t, testAPIKey, endpoint)
+	for _, name := range []string{"a", "b", "c"} {
+		if _, err := client.CreateKnowledge(ctx, name, "body", "trigger", ""); err != nil {
+			t.Fatalf("CreateKnowledge() error = %v", err)
+		}
+	}
+
+	paged := newProviderClient(t, testAPIKey, endpoint)
+	paged.PageSize = 2
+	list, err := paged.ListKnowledge(ctx)
+	if err != nil {
+		t.Fatalf("ListKnowledge() error = %v", err)
+	}
+	if len(list.Knowledge) != 3 {
+		t.Errorf("ListKnowledge() returned %d items, want 3", len(list.Knowledge))
+	}
+}
+
+func TestServer_Auth(t *testing.T) {
+	client := newProviderClient(t, "wrong-key", newTestServer(t, &server{}))
+	if _, err := client.ListKnowledge(context.Background()); !errors.Is(err, provider.ErrUnauthorized) {
+		t.Errorf("ListKnowledge() error = %v, want ErrUnauthorized", err)
+	}
+}
+
+// get sends an authenticated GET request to the list endpoint
+func get(t *testing.T, endpoint string, header http.Header) *http.Response {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, endpoint+"/knowledge", nil)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Authorization", "Bearer "+testAPIKey)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestServer_ETag(t *testing.T) {
+	endpoint := newTestServer(t, &server{})
+
+	first := get(t, endpoint, nil)
+	etag := first.Header.Get("ETag")
+	if first.StatusCode != http.StatusOK || etag == "" {
+		t.Fatalf("GET status = %d, ETag = %q, want 200 with an ETag", first.StatusCode, etag)
+	}
+
+	second := get(t, endpoint, http.Header{"If-None-Match": {etag}})
+	if second.StatusCode != http.StatusNotModified {
+		t.Errorf("conditional GET status = %d, want 304", second.StatusCode)
+	}
+}
+
+func TestServer_FaultInjection(t *testing.T) {
+	throttled := get(t, newTestServer(t, &server{throttleRate: 1}), nil)
+	if throttled.StatusCode != http.StatusTooManyRequests || throttled.Header.Get("Retry-After") == "" {
+		t.Errorf("throttled status = %d, Retry-After = %q, want 429 with Retry-After", throttled.StatusCode, throttled.Header.Get("Retry-After"))
+	}
+
+	failing := get(t, newTestServer(t, &server{errorRate: 1}), nil)
+	if failing.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("failing status = %d, want 503", failing.StatusCode)
+	}
+
+	var body provider.ErrorResponse
+	if err := json.NewDecoder(failing.Body).Decode(&body); err != nil || body.Error.Type != "server_error" {
+		t.Errorf("error body = %+v, %v, want an ErrorResponse", body, err)
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/hirosi1900day/terraform-provider-devin-knowledge/internal/provider"
+)
+
+// store holds the knowledge and folders served by the fake server. It
+// generates IDs for created knowledge and reports missing resources and
+// invalid requests with the API's status codes. It is safe for concurrent use.
+type store struct {
+	mu        sync.RWMutex
+	knowledge []provider.KnowledgeItem
+	folders   []provider.FolderItem
+	nextID    int
+
+	// now returns the creation time of new knowledge
+	now func() time.Time
+}
+
+// newStore returns a store holding the given knowledge and folders
+func newStore(state *provider.ListKnowledgeResponse) *store {
+	return &store{
+		knowledge: append([]provider.KnowledgeItem{}, state.Knowledge...),
+		folders:   append([]provider.FolderItem{}, state.Folders...),
+		now:       time.Now,
+	}
+}
+
+// list returns a copy of the current state
+func (s *store) list() *provider.ListKnowledgeResponse {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return &provider.ListKnowledgeResponse{
+		Knowledge: append([]provider.KnowledgeItem{}, s.knowledge...),
+		Folders:   append([]provider.FolderItem{}, s.folders...),
+	}
+}
+
+// create adds a knowledge item
+func (s *store) create(req provider.CreateKnowledgeRequest) (*provider.Knowledge, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.validate(req); err != nil {
+		return nil, err
+	}
+
+	item := provider.KnowledgeItem{
+		ID:                 s.newID(),
+		Name:               req.Name,
+		Body:               req.Body,
+		TriggerDescription: req.TriggerDescription,
+		ParentFolderID:     req.ParentFolderID,
+		CreatedAt:          s.now(),
+	}
+	s.knowledge = append(s.knowledge, item)
+
+	knowledge := provider.Knowledge(item)
+	return &knowledge, nil
+}
+
+// update replaces the fields of a knowledge item
+func (s *store) update(id string, req provider.UpdateKnowledgeRequest) (*provider.Knowledge, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.indexOf(id)
+	if i < 0 {
+		return nil, notFound(id)
+	}
+	if err := s.validate(provider.CreateKnowledgeRequest(req)); err != nil {
+		return nil, err
+	}
+
+	item := &s.knowledge[i]
+	item.Name = req.Name
+	item.Body = req.Body
+	item.TriggerDescription = req.TriggerDescription
+	item.ParentFolderID = req.ParentFolderID
+
+	knowledge := provider.Knowledge(*item)
+	return &knowledge, nil
+}
+
+// delete removes a knowledge item
+func (s *store) delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.indexOf(id)
+	if i < 0 {
+		return notFound(id)
+	}
+	s.knowledge = append(s.knowledge[:i], s.knowledge[i+1:]...)
+	return nil
+}
+
+// validate checks a create or update request the way the API does; s.mu
+// must be held
+func (s *store) validate(req provider.CreateKnowledgeRequest) error {
+	required := []struct{ field, value string }{
+		{"name", req.Name},
+		{"body", req.Body},
+		{"trigger_description", req.TriggerDescription},
+	}
+	for _, r := range required {
+		if r.value == "" {
+			return &provider.APIError{
+				StatusCode: http.StatusBadRequest,
+				Type:       "invalid_request_error",
+				Message:    fmt.Sprintf("%s is required", r.field),
+			}
+		}
+	}
+
+	if req.ParentFolderID == "" {
+		return nil
+	}
+	for _, folder := range s.folders {
+		if folder.ID == req.ParentFolderID {
+			return nil
+		}
+	}
+	return &provider.APIError{
+		StatusCode: http.StatusNotFound,
+		Type:       "not_found_error",
+		Message:    fmt.Sprintf("folder resource with ID '%s' not found", req.ParentFolderID),
+	}
+}
+
+// indexOf returns the position of a knowledge item, or -1; s.mu must be held
+func (s *store) indexOf(id string) int {
+	for i := range s.knowledge {
+		if s.knowledge[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// newID returns an unused knowledge ID; s.mu must be held
+func (s *store) newID() string {
+	for {
+		s.nextID++
+		id := fmt.Sprintf("knowledge-%d", s.nextID)
+		if s.indexOf(id) < 0 && !s.hasFolder(id) {
+			return id
+		}
+	}
+}
+
+// hasFolder reports whether a folder has the given ID; s.mu must be held
+func (s *store) hasFolder(id string) bool {
+	for _, folder := range s.folders {
+		if folder.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// notFound returns the API's error for a missing knowledge item
+func notFound(id string) error {
+	return &provider.APIError{
+		StatusCode: http.StatusNotFound,
+		Type:       "not_found_error",
+		Message:    fmt.Sprintf("knowledge resource with ID '%s' not found", id),
+	}
+}
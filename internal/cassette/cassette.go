@@ -0,0 +1,419 @@
+// Package cassette provides an http.RoundTripper that records HTTP exchanges
+// to a fixture file and replays them later, so client code can be tested
+// against real API responses without network access.
+//
+// Secrets are scrubbed before anything is written: configured headers, JSON
+// body fields and literal values are replaced with Redacted. Requests are
+// matched against the recording by method, path and query by default; extra
+// header and body matchers can be added.
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Redacted replaces scrubbed secrets in recordings
+const Redacted = "REDACTED"
+
+// Mode selects whether a Recorder records or replays
+type Mode int
+
+const (
+	// ModeReplay serves responses from the cassette and never touches the network
+	ModeReplay Mode = iota
+	// ModeRecord sends requests to the real transport and saves the exchanges
+	ModeRecord
+)
+
+// ModeFromEnv returns ModeRecord when the environment variable is set to
+// "record", and ModeReplay otherwise
+func ModeFromEnv(name string) Mode {
+	if os.Getenv(name) == "record" {
+		return ModeRecord
+	}
+	return ModeReplay
+}
+
+// Request is a recorded HTTP request
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// Response is a recorded HTTP response
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Interaction is a request and the response it received
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Cassette is the content of a fixture file
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Matcher reports whether an outgoing request, already scrubbed, matches a
+// recorded one
+type Matcher func(req Request, recorded Request) bool
+
+// MatchMethodAndPath matches the method, path and query string, ignoring the
+// scheme and host so that recordings work against any endpoint
+func MatchMethodAndPath(req Request, recorded Request) bool {
+	return req.Method == recorded.Method && pathAndQuery(req.URL) == pathAndQuery(recorded.URL)
+}
+
+// MatchHeaders returns a matcher requiring the named headers to be equal
+func MatchHeaders(names ...string) Matcher {
+	return func(req Request, recorded Request) bool {
+		for _, name := range names {
+			if req.Header.Get(name) != recorded.Header.Get(name) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// MatchBody matches request bodies, comparing JSON bodies semantically so
+// that field order and whitespace do not matter
+func MatchBody(req Request, recorded Request) bool {
+	var a, b interface{}
+	if json.Unmarshal([]byte(req.Body), &a) == nil && json.Unmarshal([]byte(recorded.Body), &b) == nil {
+		return reflect.DeepEqual(a, b)
+	}
+	return req.Body == recorded.Body
+}
+
+// Option configures a Recorder
+type Option func(*Recorder)
+
+// WithTransport sets the transport used in record mode (default http.DefaultTransport)
+func WithTransport(transport http.RoundTripper) Option {
+	return func(r *Recorder) {
+		r.transport = transport
+	}
+}
+
+// WithMatchers adds matchers on top of MatchMethodAndPath
+func WithMatchers(matchers ...Matcher) Option {
+	return func(r *Recorder) {
+		r.matchers = append(r.matchers, matchers...)
+	}
+}
+
+// WithScrubbedHeaders adds headers whose values are redacted (default: Authorization)
+func WithScrubbedHeaders(names ...string) Option {
+	return func(r *Recorder) {
+		r.scrubHeaders = append(r.scrubHeaders, names...)
+	}
+}
+
+// WithScrubbedFields adds JSON object keys whose values are redacted in
+// request and response bodies, at any depth
+func WithScrubbedFields(names ...string) Option {
+	return func(r *Recorder) {
+		r.scrubFields = append(r.scrubFields, names...)
+	}
+}
+
+// WithScrubbedValues redacts literal values, such as an API key, wherever
+// they appear in URLs, headers and bodies
+func WithScrubbedValues(values ...string) Option {
+	return func(r *Recorder) {
+		for _, v := range values {
+			if v != "" {
+				r.scrubValues = append(r.scrubValues, v)
+			}
+		}
+	}
+}
+
+// Recorder is an http.RoundTripper that records or replays a cassette.
+// It is safe for concurrent use.
+type Recorder struct {
+	path      string
+	mode      Mode
+	transport http.RoundTripper
+
+	matchers     []Matcher
+	scrubHeaders []string
+	scrubFields  []string
+	scrubValues  []string
+
+	mu       sync.Mutex
+	cassette Cassette
+	// used marks replayed interactions so repeated requests get the
+	// responses in recorded order
+	used []bool
+}
+
+// New returns a Recorder for the fixture file at path. In replay mode the
+// file must exist.
+func New(path string, mode Mode, opts ...Option) (*Recorder, error) {
+	r := &Recorder{
+		path:         path,
+		mode:         mode,
+		transport:    http.DefaultTransport,
+		matchers:     []Matcher{MatchMethodAndPath},
+		scrubHeaders: []string{"Authorization"},
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if mode == ModeReplay {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cassette: %w", err)
+		}
+		if err := json.Unmarshal(data, &r.cassette); err != nil {
+			return nil, fmt.Errorf("failed to decode cassette %s: %w", path, err)
+		}
+		r.used = make([]bool, len(r.cassette.Interactions))
+	}
+
+	return r, nil
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	recordedReq := r.scrubRequest(req, body)
+
+	if r.mode == ModeRecord {
+		return r.record(req, recordedReq)
+	}
+	return r.replay(req, recordedReq)
+}
+
+// record sends the request and saves the scrubbed exchange
+func (r *Recorder) record(req *http.Request, recordedReq Request) (*http.Response, error) {
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Request: recordedReq,
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Header:     r.scrubHeader(resp.Header),
+			Body:       r.scrubBody(respBody),
+		},
+	})
+	r.mu.Unlock()
+
+	return resp, nil
+}
+
+// replay returns the first unused recorded response whose request matches
+func (r *Recorder) replay(req *http.Request, recordedReq Request) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, interaction := range r.cassette.Interactions {
+		if r.used[i] || !r.matches(recordedReq, interaction.Request) {
+			continue
+		}
+		r.used[i] = true
+
+		header := interaction.Response.Header.Clone()
+		if header == nil {
+			header = make(http.Header)
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+			StatusCode:    interaction.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(strings.NewReader(interaction.Response.Body)),
+			ContentLength: int64(len(interaction.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("cassette %s has no unused interaction matching %s %s", r.path, recordedReq.Method, recordedReq.URL)
+}
+
+// matches applies every matcher
+func (r *Recorder) matches(req Request, recorded Request) bool {
+	for _, match := range r.matchers {
+		if !match(req, recorded) {
+			return false
+		}
+	}
+	return true
+}
+
+// Unused returns the recorded interactions that were never replayed, which
+// usually means the code under test stopped sending a request
+func (r *Recorder) Unused() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var unused []Interaction
+	for i, used := range r.used {
+		if !used {
+			unused = append(unused, r.cassette.Interactions[i])
+		}
+	}
+	return unused
+}
+
+// Stop saves the cassette in record mode. It does nothing in replay mode.
+func (r *Recorder) Stop() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, append(data, '\n'), 0o644)
+}
+
+// readBody reads the request body and restores it for the real transport
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// scrubRequest converts a request to its recorded, scrubbed form
+func (r *Recorder) scrubRequest(req *http.Request, body []byte) Request {
+	return Request{
+		Method: req.Method,
+		URL:    r.scrubString(req.URL.String()),
+		Header: r.scrubHeader(req.Header),
+		Body:   r.scrubBody(body),
+	}
+}
+
+// scrubHeader returns a copy of header with secrets redacted
+func (r *Recorder) scrubHeader(header http.Header) http.Header {
+	scrubbed := make(http.Header, len(header))
+	for name, values := range header {
+		redact := false
+		for _, secret := range r.scrubHeaders {
+			if strings.EqualFold(name, secret) {
+				redact = true
+				break
+			}
+		}
+		for _, v := range values {
+			if redact {
+				v = Redacted
+			}
+			scrubbed.Add(name, r.scrubString(v))
+		}
+	}
+	return scrubbed
+}
+
+// scrubBody redacts secret fields of a JSON body and literal secret values
+func (r *Recorder) scrubBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	if len(r.scrubFields) > 0 {
+		var v interface{}
+		if err := json.Unmarshal(body, &v); err == nil {
+			if scrubbed, err := json.Marshal(r.scrubJSON(v)); err == nil {
+				body = scrubbed
+			}
+		}
+	}
+	return r.scrubString(string(body))
+}
+
+// scrubJSON redacts configured object keys at any depth
+func (r *Recorder) scrubJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if r.isSecretField(key) {
+				v[key] = Redacted
+			} else {
+				v[key] = r.scrubJSON(value)
+			}
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = r.scrubJSON(v[i])
+		}
+	}
+	return v
+}
+
+// isSecretField reports whether a JSON key must be redacted
+func (r *Recorder) isSecretField(key string) bool {
+	for _, field := range r.scrubFields {
+		if strings.EqualFold(key, field) {
+			return true
+		}
+	}
+	return false
+}
+
+// scrubString replaces literal secret values
+func (r *Recorder) scrubString(s string) string {
+	for _, secret := range r.scrubValues {
+		s = strings.ReplaceAll(s, secret, Redacted)
+	}
+	return s
+}
+
+// pathAndQuery strips the scheme and host from a URL
+func pathAndQuery(rawURL string) string {
+	if i := strings.Index(rawURL, "://"); i >= 0 {
+		rest := rawURL[i+3:]
+		if j := strings.IndexAny(rest, "/?"); j >= 0 {
+			return rest[j:]
+		}
+		return ""
+	}
+	return rawURL
+}
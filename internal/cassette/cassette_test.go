@@ -0,0 +1,122 @@
+package cassette
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// send issues a request through the recorder and returns the response body
+func send(t *testing.T, rec *Recorder, method, url, body string) (*http.Response, string) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("NewRequest() error = %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer secret-key")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := (&http.Client{Transport: rec}).Do(req)
+	if err != nil {
+		t.Fatalf("%s %s error = %v", method, url, err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	return resp, string(data)
+}
+
+func TestRecorder_RecordAndReplay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("X-Request-Id", "req-1")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":"k1","token":"server-secret","echo":` + string(body) + `}`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "fixture.json")
+
+	rec, err := New(path, ModeRecord, WithScrubbedFields("token"), WithScrubbedValues("secret-key"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	send(t, rec, http.MethodPost, server.URL+"/v1/knowledge", `{"name":"a","b":1}`)
+	if err := rec.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	for _, secret := range []string{"secret-key", "server-secret"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("cassette contains secret %q:\n%s", secret, data)
+		}
+	}
+
+	// Replay against another host, with reordered JSON fields
+	server.Close()
+	replay, err := New(path, ModeReplay, WithMatchers(MatchBody, MatchHeaders("Content-Type")))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	resp, body := send(t, replay, http.MethodPost, "https://api.example.com/v1/knowledge", `{"b":1, "name":"a"}`)
+	if resp.StatusCode != http.StatusCreated || resp.Header.Get("X-Request-Id") != "req-1" {
+		t.Errorf("replayed status = %d, X-Request-Id = %q", resp.StatusCode, resp.Header.Get("X-Request-Id"))
+	}
+	if !strings.Contains(body, `"id":"k1"`) {
+		t.Errorf("replayed body = %s", body)
+	}
+	if unused := replay.Unused(); len(unused) != 0 {
+		t.Errorf("Unused() = %d interactions, want 0", len(unused))
+	}
+}
+
+func TestRecorder_ReplayMismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixture.json")
+	fixture := `{"interactions":[
+		{"request":{"method":"GET","url":"https://api.example.com/v1/knowledge"},"response":{"status_code":429}},
+		{"request":{"method":"GET","url":"https://api.example.com/v1/knowledge"},"response":{"status_code":200,"body":"{}"}},
+		{"request":{"method":"PUT","url":"https://api.example.com/v1/knowledge/k1","body":"{\"name\":\"a\"}"},"response":{"status_code":200}}
+	]}`
+	if err := os.WriteFile(path, []byte(fixture), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	rec, err := New(path, ModeReplay, WithMatchers(MatchBody))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	// Identical requests get the recorded responses in order
+	first, _ := send(t, rec, http.MethodGet, "https://api.example.com/v1/knowledge", "")
+	second, _ := send(t, rec, http.MethodGet, "https://api.example.com/v1/knowledge", "")
+	if first.StatusCode != http.StatusTooManyRequests || second.StatusCode != http.StatusOK {
+		t.Errorf("replayed statuses = %d, %d, want 429, 200", first.StatusCode, second.StatusCode)
+	}
+
+	// A different body does not match
+	req, _ := http.NewRequest(http.MethodPut, "https://api.example.com/v1/knowledge/k1", strings.NewReader(`{"name":"b"}`))
+	if _, err := (&http.Client{Transport: rec}).Do(req); err == nil {
+		t.Error("request with a different body should not match")
+	}
+	if unused := rec.Unused(); len(unused) != 1 {
+		t.Errorf("Unused() = %d interactions, want 1", len(unused))
+	}
+}
+
+func TestModeFromEnv(t *testing.T) {
+	t.Setenv("CASSETTE_TEST_MODE", "record")
+	if ModeFromEnv("CASSETTE_TEST_MODE") != ModeRecord {
+		t.Error("ModeFromEnv() should return ModeRecord")
+	}
+	t.Setenv("CASSETTE_TEST_MODE", "")
+	if ModeFromEnv("CASSETTE_TEST_MODE") != ModeReplay {
+		t.Error("ModeFromEnv() should return ModeReplay")
+	}
+}
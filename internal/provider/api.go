@@ -0,0 +1,35 @@
+package provider
+
+import (
+	"context"
+)
+
+// DevinAPI is the set of Devin API operations used by the provider's
+// resources and data sources. DevinClient talks to the real API; decorators
+// such as NewLoggingAPI wrap another DevinAPI.
+type DevinAPI interface {
+	// ListKnowledge retrieves every knowledge resource and folder
+	ListKnowledge(ctx context.Context) (*ListKnowledgeResponse, error)
+	// ListKnowledgeInFolder retrieves the knowledge resources in a folder;
+	// an empty folderID selects knowledge that is not in any folder
+	ListKnowledgeInFolder(ctx context.Context, folderID string) ([]KnowledgeItem, error)
+	// GetKnowledge retrieves a knowledge resource by ID
+	GetKnowledge(ctx context.Context, id string) (*Knowledge, error)
+	// CreateKnowledge creates a knowledge resource
+	CreateKnowledge(ctx context.Context, name, body string, triggerDescription string, parentFolderID string) (*Knowledge, error)
+	// UpdateKnowledge updates a knowledge resource
+	UpdateKnowledge(ctx context.Context, id, name, body string, triggerDescription string, parentFolderID string) (*Knowledge, error)
+	// DeleteKnowledge deletes a knowledge resource
+	DeleteKnowledge(ctx context.Context, id string) error
+	// FindKnowledge retrieves the knowledge resource with the given name in a
+	// folder; an empty parentFolderID selects knowledge that is not in any folder
+	FindKnowledge(ctx context.Context, name, parentFolderID string) (*Knowledge, error)
+	// GetFolderByID retrieves a folder by ID
+	GetFolderByID(ctx context.Context, id string) (*FolderItem, error)
+	// GetFolderByName retrieves a folder by name. If several folders share
+	// the name, the first one is returned together with the IDs of all of
+	// them; duplicateIDs is nil otherwise
+	GetFolderByName(ctx context.Context, name string) (folder *FolderItem, duplicateIDs []string, err error)
+}
+
+var _ DevinAPI = (*DevinClient)(nil)
@@ -0,0 +1,107 @@
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// loggingAPI logs every operation of the wrapped DevinAPI
+type loggingAPI struct {
+	next DevinAPI
+}
+
+// NewLoggingAPI wraps next so that every operation is logged with its
+// duration and outcome
+func NewLoggingAPI(next DevinAPI) DevinAPI {
+	return &loggingAPI{next: next}
+}
+
+// logAPICall logs a completed DevinAPI operation
+func logAPICall(ctx context.Context, operation string, duration time.Duration, err error) {
+	fields := map[string]interface{}{
+		"operation": operation,
+		"duration":  duration.String(),
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+	}
+	tflog.Debug(ctx, "Devin API operation completed", fields)
+}
+
+// logged runs fn and logs it under operation
+func logged[T any](ctx context.Context, operation string, fn func() (T, error)) (T, error) {
+	start := time.Now()
+	result, err := fn()
+	logAPICall(ctx, operation, time.Since(start), err)
+	return result, err
+}
+
+// ListKnowledge implements DevinAPI
+func (a *loggingAPI) ListKnowledge(ctx context.Context) (*ListKnowledgeResponse, error) {
+	return logged(ctx, "ListKnowledge", func() (*ListKnowledgeResponse, error) {
+		return a.next.ListKnowledge(ctx)
+	})
+}
+
+// ListKnowledgeInFolder implements DevinAPI
+func (a *loggingAPI) ListKnowledgeInFolder(ctx context.Context, folderID string) ([]KnowledgeItem, error) {
+	return logged(ctx, "ListKnowledgeInFolder", func() ([]KnowledgeItem, error) {
+		return a.next.ListKnowledgeInFolder(ctx, folderID)
+	})
+}
+
+// GetKnowledge implements DevinAPI
+func (a *loggingAPI) GetKnowledge(ctx context.Context, id string) (*Knowledge, error) {
+	return logged(ctx, "GetKnowledge", func() (*Knowledge, error) {
+		return a.next.GetKnowledge(ctx, id)
+	})
+}
+
+// CreateKnowledge implements DevinAPI
+func (a *loggingAPI) CreateKnowledge(ctx context.Context, name, body string, triggerDescription string, parentFolderID string) (*Knowledge, error) {
+	return logged(ctx, "CreateKnowledge", func() (*Knowledge, error) {
+		return a.next.CreateKnowledge(ctx, name, body, triggerDescription, parentFolderID)
+	})
+}
+
+// UpdateKnowledge implements DevinAPI
+func (a *loggingAPI) UpdateKnowledge(ctx context.Context, id, name, body string, triggerDescription string, parentFolderID string) (*Knowledge, error) {
+	return logged(ctx, "UpdateKnowledge", func() (*Knowledge, error) {
+		return a.next.UpdateKnowledge(ctx, id, name, body, triggerDescription, parentFolderID)
+	})
+}
+
+// DeleteKnowledge implements DevinAPI
+func (a *loggingAPI) DeleteKnowledge(ctx context.Context, id string) error {
+	_, err := logged(ctx, "DeleteKnowledge", func() (struct{}, error) {
+		return struct{}{}, a.next.DeleteKnowledge(ctx, id)
+	})
+	return err
+}
+
+// GetFolderByID implements DevinAPI
+func (a *loggingAPI) GetFolderByID(ctx context.Context, id string) (*FolderItem, error) {
+	return logged(ctx, "GetFolderByID", func() (*FolderItem, error) {
+		return a.next.GetFolderByID(ctx, id)
+	})
+}
+
+// FindKnowledge implements DevinAPI
+func (a *loggingAPI) FindKnowledge(ctx context.Context, name, parentFolderID string) (*Knowledge, error) {
+	return logged(ctx, "FindKnowledge", func() (*Knowledge, error) {
+		return a.next.FindKnowledge(ctx, name, parentFolderID)
+	})
+}
+
+// GetFolderByName implements DevinAPI
+func (a *loggingAPI) GetFolderByName(ctx context.Context, name string) (*FolderItem, []string, error) {
+	var duplicateIDs []string
+	folder, err := logged(ctx, "GetFolderByName", func() (*FolderItem, error) {
+		folder, ids, err := a.next.GetFolderByName(ctx, name)
+		duplicateIDs = ids
+		return folder, err
+	})
+	return folder, duplicateIDs, err
+}
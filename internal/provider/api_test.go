@@ -0,0 +1,59 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+)
+
+func TestLoggingAPI_LogsOperations(t *testing.T) {
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+	api := NewLoggingAPI(newFakeBackend())
+
+	created, err := api.CreateKnowledge(ctx, "Knowledge", "body", "trigger", "")
+	if err != nil {
+		t.Fatalf("CreateKnowledge() error = %v", err)
+	}
+	if _, err := api.GetKnowledge(ctx, created.ID); err != nil {
+		t.Fatalf("GetKnowledge() error = %v", err)
+	}
+	if _, err := api.GetKnowledge(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("GetKnowledge(missing) error = %v, want ErrNotFound", err)
+	}
+
+	logs := output.String()
+	if n := strings.Count(logs, "Devin API operation completed"); n != 3 {
+		t.Errorf("logged %d operations, want 3:\n%s", n, logs)
+	}
+	for _, want := range []string{`"operation":"CreateKnowledge"`, `"operation":"GetKnowledge"`, "not found"} {
+		if !strings.Contains(logs, want) {
+			t.Errorf("logs do not contain %s:\n%s", want, logs)
+		}
+	}
+}
+
+func TestKnowledgeResourceConfigure_AcceptsDevinAPI(t *testing.T) {
+	r := &KnowledgeResource{}
+	fake := newFakeBackend()
+
+	var resp resource.ConfigureResponse
+	r.Configure(context.Background(), resource.ConfigureRequest{ProviderData: NewLoggingAPI(fake)}, &resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Configure() unexpected error: %v", resp.Diagnostics)
+	}
+	if r.client == nil {
+		t.Fatal("Configure() did not set the client")
+	}
+
+	resp = resource.ConfigureResponse{}
+	r.Configure(context.Background(), resource.ConfigureRequest{ProviderData: "not an API"}, &resp)
+	if !resp.Diagnostics.HasError() {
+		t.Error("Configure() should reject provider data that is not a DevinAPI")
+	}
+}
@@ -0,0 +1,135 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	// Default number of consecutive failed requests that opens the circuit
+	defaultCircuitThreshold = 5
+	// Default time the circuit stays open before a probe request is allowed
+	defaultCircuitCooldown = 30 * time.Second
+)
+
+// circuitBreaker stops sending requests to an API that keeps failing.
+// After threshold consecutive failures it opens and rejects requests with
+// ErrCircuitOpen for the cooldown, then lets a single probe request through:
+// success closes the circuit, failure opens it for another cooldown.
+// A nil breaker allows every request.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	failures int
+	open     bool
+	openedAt time.Time
+	// A probe request is in flight after the cooldown
+	probing bool
+	// Last failure, included in the error of rejected requests
+	lastErr string
+}
+
+// SetCircuitBreaker configures the circuit breaker. A threshold of 0 or less
+// disables it.
+func (c *DevinClient) SetCircuitBreaker(threshold int, cooldown time.Duration) {
+	if threshold <= 0 {
+		c.breaker = nil
+		return
+	}
+	c.breaker = &circuitBreaker{threshold: threshold, cooldown: cooldown, now: time.Now}
+}
+
+// allow reports whether a request may be sent, returning an error matching
+// ErrCircuitOpen if not, and whether the request is the probe after the
+// cooldown. Every allowed request must be followed by record or release.
+func (b *circuitBreaker) allow() (bool, error) {
+	if b == nil {
+		return false, nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.open {
+		return false, nil
+	}
+	if remaining := b.cooldown - b.now().Sub(b.openedAt); remaining > 0 {
+		return false, fmt.Errorf("%w: %d consecutive Devin API requests failed (last error: %s); requests resume in %s",
+			ErrCircuitOpen, b.failures, b.lastErr, remaining.Round(time.Second))
+	}
+	if b.probing {
+		return false, fmt.Errorf("%w: %d consecutive Devin API requests failed (last error: %s); waiting for a probe request to succeed",
+			ErrCircuitOpen, b.failures, b.lastErr)
+	}
+	b.probing = true
+	return true, nil
+}
+
+// release gives up an allowed request without sending it, letting another
+// request probe the API if it was the probe
+func (b *circuitBreaker) release(probe bool) {
+	if b == nil || !probe {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// record updates the breaker with the result of an allowed request. Network
+// errors and 5xx responses are failures; any other response shows that the
+// API is up. Requests the caller gave up on say nothing about the API.
+func (b *circuitBreaker) record(ctx context.Context, probe bool, resp *http.Response, err error) {
+	if b == nil {
+		return
+	}
+
+	var failure string
+	switch {
+	case err != nil && ctx.Err() != nil:
+		b.release(probe)
+		return
+	case err != nil:
+		failure = err.Error()
+	case resp.StatusCode >= 500:
+		failure = fmt.Sprintf("status code %d", resp.StatusCode)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if probe {
+		b.probing = false
+	}
+
+	if failure == "" {
+		if b.open {
+			tflog.Info(ctx, "Devin API is responding again, closing circuit breaker")
+		}
+		b.failures = 0
+		b.open = false
+		return
+	}
+
+	b.failures++
+	b.lastErr = failure
+	if !probe && (b.open || b.failures < b.threshold) {
+		return
+	}
+	if !b.open {
+		tflog.Warn(ctx, "Devin API keeps failing, opening circuit breaker", map[string]interface{}{
+			"failures": b.failures,
+			"cooldown": b.cooldown.String(),
+			"error":    b.lastErr,
+		})
+	}
+	b.open = true
+	b.openedAt = b.now()
+}
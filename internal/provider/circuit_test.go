@@ -0,0 +1,145 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// failingServer is a local Devin API stand-in that returns 503 until it is
+// told to recover, counting the requests that reach it
+type failingServer struct {
+	*httptest.Server
+	healthy atomic.Bool
+	calls   atomic.Int32
+}
+
+func newFailingServer(t *testing.T) *failingServer {
+	t.Helper()
+	s := &failingServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.calls.Add(1)
+		if !s.healthy.Load() {
+			http.Error(w, `{"error":{"message":"unavailable","type":"server_error"}}`, http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"knowledge":[],"folders":[]}`)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// newBreakerClient returns a client for endpoint without retries, whose
+// circuit breaker opens after threshold failures and uses the clock now
+func newBreakerClient(t *testing.T, endpoint string, threshold int, now *time.Time) *DevinClient {
+	t.Helper()
+	client, err := NewClient("key", endpoint)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	client.MaxRetries = 0
+	client.SetCircuitBreaker(threshold, time.Minute)
+	client.breaker.now = func() time.Time { return *now }
+	return client
+}
+
+func TestCircuitBreaker_OpensAndRecovers(t *testing.T) {
+	server := newFailingServer(t)
+	now := time.Now()
+	client := newBreakerClient(t, server.URL, 3, &now)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.sendRequest(ctx, http.MethodGet, "/knowledge", nil); !errors.Is(err, ErrServerError) {
+			t.Fatalf("request %d error = %v, want ErrServerError", i+1, err)
+		}
+	}
+
+	// Open: requests fail fast without reaching the server
+	_, err := client.sendRequest(ctx, http.MethodGet, "/knowledge", nil)
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("error = %v, want ErrCircuitOpen", err)
+	}
+	if calls := server.calls.Load(); calls != 3 {
+		t.Errorf("server received %d requests, want 3", calls)
+	}
+	if detail := apiErrorDetail(err); !strings.Contains(detail, "circuit_breaker_cooldown") {
+		t.Errorf("apiErrorDetail() = %q, want a hint about circuit_breaker_cooldown", detail)
+	}
+
+	// After the cooldown a probe is let through; it fails and reopens the circuit
+	now = now.Add(time.Minute)
+	if _, err := client.sendRequest(ctx, http.MethodGet, "/knowledge", nil); !errors.Is(err, ErrServerError) {
+		t.Fatalf("probe error = %v, want ErrServerError", err)
+	}
+	if _, err := client.sendRequest(ctx, http.MethodGet, "/knowledge", nil); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("error after failed probe = %v, want ErrCircuitOpen", err)
+	}
+
+	// A successful probe closes the circuit
+	server.healthy.Store(true)
+	now = now.Add(time.Minute)
+	for i := 0; i < 2; i++ {
+		if _, err := client.sendRequest(ctx, http.MethodGet, "/knowledge", nil); err != nil {
+			t.Fatalf("request %d after recovery error = %v", i+1, err)
+		}
+	}
+	if calls := server.calls.Load(); calls != 6 {
+		t.Errorf("server received %d requests, want 6", calls)
+	}
+}
+
+func TestCircuitBreaker_SingleProbe(t *testing.T) {
+	now := time.Now()
+	b := &circuitBreaker{threshold: 1, cooldown: time.Minute, now: func() time.Time { return now }}
+	ctx := context.Background()
+
+	b.record(ctx, false, nil, errors.New("connection refused"))
+	if _, err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("allow() error = %v, want ErrCircuitOpen", err)
+	}
+
+	now = now.Add(time.Minute)
+	probe, err := b.allow()
+	if err != nil || !probe {
+		t.Fatalf("allow() = %v, %v, want the probe", probe, err)
+	}
+	// Other requests keep failing fast while the probe is in flight
+	if _, err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("allow() during probe error = %v, want ErrCircuitOpen", err)
+	}
+
+	// A probe abandoned by its caller lets the next request probe
+	b.release(probe)
+	if probe, err := b.allow(); err != nil || !probe {
+		t.Errorf("allow() after release = %v, %v, want the probe", probe, err)
+	}
+}
+
+func TestCircuitBreaker_IgnoresClientErrorsAndCancellation(t *testing.T) {
+	now := time.Now()
+	b := &circuitBreaker{threshold: 2, cooldown: time.Minute, now: func() time.Time { return now }}
+	ctx := context.Background()
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	b.record(ctx, false, nil, errors.New("connection refused"))
+	b.record(cancelled, false, nil, context.Canceled)
+	b.record(ctx, false, &http.Response{StatusCode: http.StatusNotFound}, nil)
+	b.record(ctx, false, nil, errors.New("connection refused"))
+	if _, err := b.allow(); err != nil {
+		t.Errorf("allow() error = %v, a 4xx response should reset the failure count", err)
+	}
+
+	var disabled *circuitBreaker
+	disabled.record(ctx, false, nil, errors.New("connection refused"))
+	if _, err := disabled.allow(); err != nil {
+		t.Errorf("disabled breaker allow() error = %v", err)
+	}
+}
@@ -2,35 +2,82 @@ package provider
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/time/rate"
 )
 
 const (
-	// Base URL for Devin API
-	baseURL = "https://api.devin.ai/v1"
+	// Default base URL for Devin API
+	defaultEndpoint = "https://api.devin.ai/v1"
+	// Product name sent in the User-Agent header
+	userAgentProduct = "terraform-provider-devin"
 )
 
 // DevinClient is a client for interacting with the Devin API
 type DevinClient struct {
 	APIKey     string
 	HTTPClient *http.Client
+	// Base URL of the Devin API, without a trailing slash
+	BaseURL string
+	// User-Agent header sent with every request
+	UserAgent string
 
 	// Cache for knowledge list to avoid rate limiting
-	knowledgeCache     *ListKnowledgeResponse
+	knowledgeCache     *knowledgeSnapshot
 	knowledgeCacheMu   sync.RWMutex
 	knowledgeCacheTime time.Time
+	// Incremented on invalidation so that in-flight fetches do not restore old data
+	knowledgeCacheGen uint64
+	// In-flight refresh shared by concurrent callers
+	knowledgeRefresh   *knowledgeRefresh
+	knowledgeRefreshMu sync.Mutex
+	// Optional on-disk copy of the knowledge list shared between processes
+	diskCache *diskCache
 	// Cache TTL (default: 5 minutes for terraform plan duration)
 	CacheTTL time.Duration
+	// How long an expired snapshot may still be served while it is
+	// refreshed in the background (default: 5 minutes)
+	CacheStaleTTL time.Duration
+
+	// Maximum number of retries for transient failures (default: 3)
+	MaxRetries int
+	// Upper bound for a single wait between retries (default: 30 seconds)
+	RetryMaxWait time.Duration
+	// Number of knowledge items requested per page; 0 uses the API default
+	PageSize int
+	// Check responses for unknown and missing fields and report them to the
+	// operation that made the request, see decodeResponse
+	StrictDecoding bool
+
+	// Initial backoff between retries, doubled on every attempt
+	retryMinWait time.Duration
+
+	// Client-side token bucket shared by all resources using this client
+	limiter *rate.Limiter
+	// Fails requests fast while the API keeps failing; nil when disabled
+	breaker *circuitBreaker
+
+	// Request, retry and cache counters reported by Usage
+	usage usageCounters
+	// Response schema differences already reported with StrictDecoding
+	drift schemaDrift
 }
 
 // Knowledge represents a Devin knowledge resource
 type Knowledge struct {
-	ID                 string    `json:"id"`
+	ID                 string    `json:"id" strict:"required"`
 	Name               string    `json:"name"`
 	Body               string    `json:"body"`                       // Required
 	TriggerDescription string    `json:"trigger_description"`        // Required
@@ -40,24 +87,24 @@ type Knowledge struct {
 
 // ListKnowledgeResponse represents the response from the knowledge list API
 type ListKnowledgeResponse struct {
-	Knowledge []KnowledgeItem `json:"knowledge"`
+	Knowledge []KnowledgeItem `json:"knowledge" strict:"required"`
 	Folders   []FolderItem    `json:"folders"`
 }
 
 // KnowledgeItem represents a knowledge item
 type KnowledgeItem struct {
-	ID                 string    `json:"id"`
-	Name               string    `json:"name"`
-	Body               string    `json:"body"`                       // Required
-	TriggerDescription string    `json:"trigger_description"`        // Required
-	ParentFolderID     string    `json:"parent_folder_id,omitempty"` // Optional
+	ID                 string    `json:"id" strict:"required"`
+	Name               string    `json:"name" strict:"required"`
+	Body               string    `json:"body" strict:"required"`                // Required
+	TriggerDescription string    `json:"trigger_description" strict:"required"` // Required
+	ParentFolderID     string    `json:"parent_folder_id,omitempty"`            // Optional
 	CreatedAt          time.Time `json:"created_at"`
 }
 
 // FolderItem represents a folder item
 type FolderItem struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
+	ID          string    `json:"id" strict:"required"`
+	Name        string    `json:"name" strict:"required"`
 	Description string    `json:"description,omitempty"`
 	CreatedAt   time.Time `json:"created_at"`
 }
@@ -86,162 +133,292 @@ type ErrorResponse struct {
 	} `json:"error"`
 }
 
-// NewClient creates a new DevinClient
-func NewClient(apiKey string) *DevinClient {
+// NewClient creates a new DevinClient.
+// An empty endpoint selects the public Devin API.
+func NewClient(apiKey, endpoint string) (*DevinClient, error) {
+	baseURL, err := normalizeEndpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
 	return &DevinClient{
-		APIKey: apiKey,
+		APIKey:    apiKey,
+		BaseURL:   baseURL,
+		UserAgent: userAgent("", "", ""),
 		HTTPClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
-		CacheTTL: 5 * time.Minute, // Default cache TTL
-	}
+		CacheTTL:      5 * time.Minute, // Default cache TTL
+		CacheStaleTTL: 5 * time.Minute,
+		MaxRetries:    defaultMaxRetries,
+		RetryMaxWait:  defaultRetryMaxWait,
+		retryMinWait:  defaultRetryMinWait,
+		limiter:       rate.NewLimiter(rate.Inf, 0),
+		breaker:       &circuitBreaker{threshold: defaultCircuitThreshold, cooldown: defaultCircuitCooldown, now: time.Now},
+	}, nil
 }
 
-// InvalidateCache clears the knowledge cache
-func (c *DevinClient) InvalidateCache() {
-	c.knowledgeCacheMu.Lock()
-	defer c.knowledgeCacheMu.Unlock()
-	c.knowledgeCache = nil
-	c.knowledgeCacheTime = time.Time{}
+// userAgent builds the User-Agent header identifying the provider, e.g.
+// "terraform-provider-devin/0.0.7 (+terraform 1.9.5) team-a"
+func userAgent(version, terraformVersion, suffix string) string {
+	if version == "" {
+		version = "dev"
+	}
+	ua := userAgentProduct + "/" + version
+	if terraformVersion != "" {
+		ua += " (+terraform " + terraformVersion + ")"
+	}
+	if suffix = strings.TrimSpace(suffix); suffix != "" {
+		ua += " " + suffix
+	}
+	return ua
 }
 
-// isCacheValid checks if the cache is still valid
-func (c *DevinClient) isCacheValid() bool {
-	if c.knowledgeCache == nil {
-		return false
+// normalizeEndpoint validates that the endpoint is an absolute HTTP(S) URL
+// and strips any trailing slash so paths can be appended to it
+func normalizeEndpoint(endpoint string) (string, error) {
+	if endpoint == "" {
+		return defaultEndpoint, nil
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid endpoint %q: %w", endpoint, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("invalid endpoint %q: scheme must be http or https", endpoint)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("invalid endpoint %q: host is missing", endpoint)
 	}
-	return time.Since(c.knowledgeCacheTime) < c.CacheTTL
+	if u.RawQuery != "" || u.Fragment != "" {
+		return "", fmt.Errorf("invalid endpoint %q: query and fragment are not allowed", endpoint)
+	}
+
+	return strings.TrimRight(u.String(), "/"), nil
 }
 
-// sendRequest is a common function for sending requests
-func (c *DevinClient) sendRequest(method, path string, body interface{}) ([]byte, error) {
-	url := baseURL + path
+// sendRequest is a common function for sending requests.
+// Transient failures (429, 5xx and network errors) are retried with
+// exponential backoff; non-idempotent methods are only retried on 429
+// unless the caller opts in with withNonIdempotentRetry.
+func (c *DevinClient) sendRequest(ctx context.Context, method, path string, body interface{}, opts ...requestOption) (_ []byte, err error) {
+	ctx, span := startSpan(ctx, method+" "+spanRoute(path),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrHTTPMethod.String(method), attrURLPath.String(path)),
+	)
+	defer func() { endRequestSpan(span, err) }()
+
+	var options requestOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
 
-	var reqBody io.Reader
+	reqURL := c.BaseURL + path
+
+	var jsonData []byte
 	if body != nil {
-		jsonData, err := json.Marshal(body)
+		var err error
+		jsonData, err = json.Marshal(body)
 		if err != nil {
 			return nil, fmt.Errorf("failed to JSON encode request body: %w", err)
 		}
-		reqBody = bytes.NewBuffer(jsonData)
 	}
 
-	req, err := http.NewRequest(method, url, reqBody)
+	retryAll := isIdempotentMethod(method) || options.retryNonIdempotent
+
+	// Requests and responses are logged to the devin_api subsystem with
+	// credentials masked
+	ctx = c.withHTTPLogging(ctx)
+
+	for attempt := 0; ; attempt++ {
+		probe, err := c.breaker.allow()
+		if err != nil {
+			return nil, err
+		}
+		if attempt > 0 {
+			c.usage.retries.Add(1)
+		}
+		if err := c.waitForRateLimit(ctx, method, path); err != nil {
+			c.breaker.release(probe)
+			return nil, err
+		}
+
+		resp, respBody, err := c.doRequest(ctx, method, reqURL, jsonData, options.header)
+		c.breaker.record(ctx, probe, resp, err)
+		span.SetAttributes(attrRetryCount.Int(attempt))
+		if resp != nil {
+			span.SetAttributes(attrHTTPStatus.Int(resp.StatusCode))
+		}
+
+		if options.mayBeApplied != nil && (err != nil || resp.StatusCode >= 500) {
+			*options.mayBeApplied = true
+		}
+
+		var wait time.Duration
+		switch {
+		case err != nil:
+			// Network errors are retried unless the caller gave up
+			if ctx.Err() != nil || !retryAll || attempt >= c.MaxRetries {
+				return nil, err
+			}
+			wait = c.backoff(attempt)
+		case resp.StatusCode >= 400:
+			err = newAPIError(resp, respBody)
+			if !isRetryableStatus(resp.StatusCode, retryAll) || attempt >= c.MaxRetries {
+				return nil, err
+			}
+			wait = c.retryWait(attempt, resp.Header)
+		default:
+			if options.response != nil {
+				*options.response = resp
+			}
+			return respBody, nil
+		}
+
+		tflog.Warn(ctx, "Retrying Devin API request", map[string]interface{}{
+			"method":  method,
+			"path":    path,
+			"attempt": attempt + 1,
+			"wait":    wait.String(),
+			"error":   err.Error(),
+		})
+
+		// Give up now rather than sleep into the operation's timeout
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return nil, fmt.Errorf("%w (gave up retrying: %w before the next attempt in %s)", err, context.DeadlineExceeded, wait)
+		}
+		if sleepErr := sleepContext(ctx, wait); sleepErr != nil {
+			return nil, fmt.Errorf("%w (gave up retrying: %w)", err, sleepErr)
+		}
+	}
+}
+
+// doRequest performs a single HTTP round trip and reads the whole response body
+func (c *DevinClient) doRequest(ctx context.Context, method, reqURL string, jsonData []byte, header http.Header) (*http.Response, []byte, error) {
+	var reqBody io.Reader
+	if jsonData != nil {
+		reqBody = bytes.NewReader(jsonData)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+		return nil, nil, fmt.Errorf("failed to create HTTP request: %w", err)
 	}
 
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.APIKey))
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", c.UserAgent)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	c.logRequest(ctx, req, jsonData)
+	start := time.Now()
+	c.usage.requests.Add(1)
+	c.usage.bytesSent.Add(int64(len(jsonData)))
 
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute HTTP request: %w", err)
+		logRequestError(ctx, req, err, time.Since(start))
+		return nil, nil, fmt.Errorf("failed to execute HTTP request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		logRequestError(ctx, req, err, time.Since(start))
+		return nil, nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
-	if resp.StatusCode >= 400 {
-		var errResp ErrorResponse
-		if err := json.Unmarshal(respBody, &errResp); err == nil {
-			return nil, fmt.Errorf("API error: %s (%s)", errResp.Error.Message, errResp.Error.Type)
-		}
-		return nil, fmt.Errorf("API error: status code %d", resp.StatusCode)
+	c.logResponse(ctx, req, resp, respBody, time.Since(start))
+	c.usage.bytesReceived.Add(int64(len(respBody)))
+	if resp.StatusCode == http.StatusTooManyRequests {
+		c.usage.rateLimited.Add(1)
 	}
 
-	return respBody, nil
+	return resp, respBody, nil
 }
 
 // ListKnowledge retrieves a list of knowledge resources
-// Results are cached to avoid rate limiting during terraform plan/apply
-func (c *DevinClient) ListKnowledge() (*ListKnowledgeResponse, error) {
-	// Return mock data for demo (development/testing)
-	if IsMockClient(c.APIKey) {
-		return GetMockKnowledgeList(), nil
+// Results are cached to avoid rate limiting during terraform plan/apply.
+// Concurrent callers share a single request, and expired results are served
+// while they are refreshed in the background (see CacheStaleTTL).
+func (c *DevinClient) ListKnowledge(ctx context.Context) (*ListKnowledgeResponse, error) {
+	snap, err := c.knowledgeFromCache(ctx)
+	if err != nil {
+		return nil, err
 	}
+	return snap.list, nil
+}
 
-	// Check cache first
-	c.knowledgeCacheMu.RLock()
-	if c.isCacheValid() {
-		cached := c.knowledgeCache
-		c.knowledgeCacheMu.RUnlock()
-		return cached, nil
+// fetchKnowledgeList downloads the knowledge list, bypassing the in-memory cache.
+// When the disk cache is enabled a fresh persisted copy is used instead of the
+// API, and downloaded lists are persisted unless a mutation happened since
+// the cache generation gen. If prev carries validators the request is made
+// conditional, and prev is returned when the server reports no change.
+func (c *DevinClient) fetchKnowledgeList(ctx context.Context, gen uint64, prev *knowledgeSnapshot) (*knowledgeSnapshot, error) {
+	if c.diskCache != nil {
+		// A copy that would not be fresh in memory is ignored: serving it
+		// would make every lookup refresh it again from the same file
+		if snap, ok := c.diskCache.load(ctx); ok && time.Since(snap.fetchedAt) < c.CacheTTL {
+			return snap, nil
+		}
 	}
-	c.knowledgeCacheMu.RUnlock()
 
-	// Cache miss or expired, fetch from API
-	c.knowledgeCacheMu.Lock()
-	defer c.knowledgeCacheMu.Unlock()
-
-	// Double-check after acquiring write lock (another goroutine might have updated)
-	if c.isCacheValid() {
-		return c.knowledgeCache, nil
+	var conditional knowledgeValidators
+	if prev != nil {
+		conditional = prev.validators
 	}
 
-	// Normal processing
-	respBody, err := c.sendRequest("GET", "/knowledge", nil)
+	response, validators, notModified, err := c.fetchAllKnowledgePages(ctx, conditional)
 	if err != nil {
 		return nil, err
 	}
 
-	var response ListKnowledgeResponse
-	if err := json.Unmarshal(respBody, &response); err != nil {
-		return nil, fmt.Errorf("failed to decode JSON response: %w", err)
+	var snap *knowledgeSnapshot
+	if notModified {
+		if conditional.empty() {
+			return nil, errors.New("knowledge list request returned 304 Not Modified without being conditional")
+		}
+		tflog.Debug(ctx, "Knowledge list not modified, reusing cached snapshot", map[string]interface{}{
+			"etag":          conditional.ETag,
+			"last_modified": conditional.LastModified,
+		})
+		revalidated := *prev
+		revalidated.fetchedAt = time.Now()
+		snap = &revalidated
+	} else {
+		snap = newKnowledgeSnapshot(response)
+		snap.validators = validators
+		snap.fetchedAt = time.Now()
 	}
 
-	// Update cache
-	c.knowledgeCache = &response
-	c.knowledgeCacheTime = time.Now()
+	if c.diskCache != nil {
+		c.diskCache.save(ctx, snap, func() bool {
+			return c.isCurrentGeneration(gen)
+		})
+	}
 
-	return &response, nil
+	return snap, nil
 }
 
 // GetKnowledge retrieves a knowledge resource by ID
 // Note: Currently, the Devin API does not explicitly expose a dedicated endpoint
 // for retrieving individual knowledge resources, so we use the List API to extract
 // a specific knowledge resource by ID
-func (c *DevinClient) GetKnowledge(id string) (*Knowledge, error) {
-	// Return mock data for demo (development/testing)
-	if IsMockClient(c.APIKey) {
-		return GetMockKnowledge(id)
-	}
-
-	// Normal processing
+func (c *DevinClient) GetKnowledge(ctx context.Context, id string) (*Knowledge, error) {
 	// Use the list API to get all knowledge resources
-	response, err := c.ListKnowledge()
+	snap, err := c.knowledgeFromCache(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("error occurred while retrieving knowledge list: %w", err)
 	}
 
-	// Search for knowledge resource with matching ID
-	for _, item := range response.Knowledge {
-		if item.ID == id {
-			return &Knowledge{
-				ID:                 item.ID,
-				Name:               item.Name,
-				Body:               item.Body,
-				TriggerDescription: item.TriggerDescription,
-				ParentFolderID:     item.ParentFolderID,
-				CreatedAt:          item.CreatedAt,
-			}, nil
-		}
-	}
-
-	return nil, fmt.Errorf("knowledge resource with ID '%s' not found", id)
+	return snap.getKnowledge(id)
 }
 
 // CreateKnowledge creates a new knowledge resource
-func (c *DevinClient) CreateKnowledge(name, body string, triggerDescription string, parentFolderID string) (*Knowledge, error) {
-	// Return mock data for demo (development/testing)
-	if IsMockClient(c.APIKey) {
-		return CreateMockKnowledge(name, body, triggerDescription, parentFolderID), nil
-	}
-
-	// Normal processing
+func (c *DevinClient) CreateKnowledge(ctx context.Context, name, body string, triggerDescription string, parentFolderID string) (*Knowledge, error) {
 	reqBody := CreateKnowledgeRequest{
 		Name:               name,
 		Body:               body,
@@ -249,30 +426,36 @@ func (c *DevinClient) CreateKnowledge(name, body string, triggerDescription stri
 		ParentFolderID:     parentFolderID,
 	}
 
-	respBody, err := c.sendRequest("POST", "/knowledge", reqBody)
+	var mayBeApplied bool
+	respBody, err := c.sendRequest(ctx, "POST", "/knowledge", reqBody, withMayBeApplied(&mayBeApplied))
 	if err != nil {
+		// The knowledge may have been created anyway, so the cached list can
+		// no longer be trusted
+		if mayBeApplied {
+			c.InvalidateCache(ctx)
+		}
 		return nil, err
 	}
 
 	var knowledge Knowledge
-	if err := json.Unmarshal(respBody, &knowledge); err != nil {
+	if err := c.decodeResponse(ctx, respBody, &knowledge); err != nil {
+		// The knowledge was created but we cannot tell what it looks like
+		c.InvalidateCache(ctx)
 		return nil, fmt.Errorf("failed to decode JSON response: %w", err)
 	}
 
-	// Invalidate cache after creating a new knowledge
-	c.InvalidateCache()
+	// Write the new knowledge through to the cache
+	if knowledge.ID == "" {
+		c.InvalidateCache(ctx)
+	} else {
+		c.cacheKnowledgeUpsert(ctx, mergeKnowledgeResponse(knowledge, reqBody))
+	}
 
 	return &knowledge, nil
 }
 
 // UpdateKnowledge updates a knowledge resource
-func (c *DevinClient) UpdateKnowledge(id, name, body string, triggerDescription string, parentFolderID string) (*Knowledge, error) {
-	// Return mock data for demo (development/testing)
-	if IsMockClient(c.APIKey) {
-		return UpdateMockKnowledge(id, name, body, triggerDescription, parentFolderID), nil
-	}
-
-	// Normal processing
+func (c *DevinClient) UpdateKnowledge(ctx context.Context, id, name, body string, triggerDescription string, parentFolderID string) (*Knowledge, error) {
 	reqBody := UpdateKnowledgeRequest{
 		Name:               name,
 		Body:               body,
@@ -281,92 +464,99 @@ func (c *DevinClient) UpdateKnowledge(id, name, body string, triggerDescription
 	}
 
 	path := fmt.Sprintf("/knowledge/%s", id)
-	respBody, err := c.sendRequest("PUT", path, reqBody)
+	var mayBeApplied bool
+	respBody, err := c.sendRequest(ctx, "PUT", path, reqBody, withMayBeApplied(&mayBeApplied))
 	if err != nil {
+		// The knowledge may have been updated anyway
+		if mayBeApplied {
+			c.InvalidateCache(ctx)
+		}
 		return nil, err
 	}
 
 	var knowledge Knowledge
-	if err := json.Unmarshal(respBody, &knowledge); err != nil {
+	if err := c.decodeResponse(ctx, respBody, &knowledge); err != nil {
+		// The knowledge was updated but we cannot tell what it looks like
+		c.InvalidateCache(ctx)
 		return nil, fmt.Errorf("failed to decode JSON response: %w", err)
 	}
 
-	// Invalidate cache after updating knowledge
-	c.InvalidateCache()
+	// Write the updated knowledge through to the cache
+	if knowledge.ID != id {
+		c.InvalidateCache(ctx)
+	} else {
+		c.cacheKnowledgeUpsert(ctx, mergeKnowledgeResponse(knowledge, CreateKnowledgeRequest(reqBody)))
+	}
 
 	return &knowledge, nil
 }
 
-// DeleteKnowledge deletes a knowledge resource
-func (c *DevinClient) DeleteKnowledge(id string) error {
-	// Return mock data for demo (development/testing)
-	if IsMockClient(c.APIKey) {
-		return nil
+// FindKnowledge retrieves the knowledge resource with the given name in a folder
+// from the knowledge list. It returns an error matching ErrNotFound when there
+// is none and ErrDuplicateKnowledgeName when the name is ambiguous.
+func (c *DevinClient) FindKnowledge(ctx context.Context, name, parentFolderID string) (*Knowledge, error) {
+	snap, err := c.knowledgeFromCache(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error occurred while retrieving knowledge list: %w", err)
 	}
 
-	// Normal processing
+	return snap.findKnowledge(name, parentFolderID)
+}
+
+// DeleteKnowledge deletes a knowledge resource
+func (c *DevinClient) DeleteKnowledge(ctx context.Context, id string) error {
 	path := fmt.Sprintf("/knowledge/%s", id)
-	_, err := c.sendRequest("DELETE", path, nil)
-	if err != nil {
+	var mayBeApplied bool
+	_, err := c.sendRequest(ctx, "DELETE", path, nil, withMayBeApplied(&mayBeApplied))
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		// The knowledge may have been deleted anyway
+		if mayBeApplied {
+			c.InvalidateCache(ctx)
+		}
 		return err
 	}
 
-	// Invalidate cache after deleting knowledge
-	c.InvalidateCache()
+	// Remove the knowledge from the cache; a 404 means it is already gone too
+	c.cacheKnowledgeDelete(ctx, id)
 
-	return nil
+	return err
 }
 
 // GetFolderByID retrieves a folder resource by ID
 // Note: Currently, the Devin API does not explicitly expose a dedicated endpoint
 // for retrieving individual folder resources, so we use the List API to extract
 // a specific folder resource by ID
-func (c *DevinClient) GetFolderByID(id string) (*FolderItem, error) {
-	// Return mock data for demo (development/testing)
-	if IsMockClient(c.APIKey) {
-		return GetMockFolderByID(id)
-	}
-
-	// Normal processing
+func (c *DevinClient) GetFolderByID(ctx context.Context, id string) (*FolderItem, error) {
 	// Use the list API to get all knowledge resources (which includes folders)
-	response, err := c.ListKnowledge()
+	snap, err := c.knowledgeFromCache(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("error occurred while retrieving folder list: %w", err)
 	}
 
-	// Search for folder resource with matching ID
-	for _, item := range response.Folders {
-		if item.ID == id {
-			return &item, nil
-		}
-	}
-
-	return nil, fmt.Errorf("folder resource with ID '%s' not found", id)
+	return snap.getFolderByID(id)
 }
 
 // GetFolderByName retrieves a folder resource by name
 // Note: Currently, the Devin API does not explicitly expose a dedicated endpoint
 // for retrieving individual folder resources, so we use the List API to extract
 // a specific folder resource by name
-func (c *DevinClient) GetFolderByName(name string) (*FolderItem, error) {
-	// Return mock data for demo (development/testing)
-	if IsMockClient(c.APIKey) {
-		return GetMockFolderByName(name)
-	}
-
-	// Normal processing
+func (c *DevinClient) GetFolderByName(ctx context.Context, name string) (*FolderItem, []string, error) {
 	// Use the list API to get all knowledge resources (which includes folders)
-	response, err := c.ListKnowledge()
+	snap, err := c.knowledgeFromCache(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("error occurred while retrieving folder list: %w", err)
+		return nil, nil, fmt.Errorf("error occurred while retrieving folder list: %w", err)
 	}
 
-	// Search for folder resource with matching name
-	for _, item := range response.Folders {
-		if item.Name == name {
-			return &item, nil
-		}
+	return snap.getFolderByName(name)
+}
+
+// ListKnowledgeInFolder retrieves the knowledge resources whose parent is the given folder.
+// An empty folderID returns knowledge that is not in any folder.
+func (c *DevinClient) ListKnowledgeInFolder(ctx context.Context, folderID string) ([]KnowledgeItem, error) {
+	snap, err := c.knowledgeFromCache(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error occurred while retrieving knowledge list: %w", err)
 	}
 
-	return nil, fmt.Errorf("folder resource with name '%s' not found", name)
+	return snap.knowledgeInFolder(folderID), nil
 }
@@ -1,11 +1,38 @@
 package provider
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
+// roundTripFunc adapts a function to http.RoundTripper for stubbing the API
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// newTestClient creates a client for the default endpoint or fails the test
+func newTestClient(t *testing.T, apiKey string) *DevinClient {
+	t.Helper()
+	client, err := NewClient(apiKey, "")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return client
+}
+
 func TestNewClient(t *testing.T) {
-	client := NewClient("test-api-key")
+	client := newTestClient(t, "test-api-key")
 	if client == nil {
 		t.Fatalf("NewClient() returned nil")
 	}
@@ -15,132 +42,381 @@ func TestNewClient(t *testing.T) {
 	if client.HTTPClient == nil {
 		t.Errorf("NewClient() HTTPClient is nil")
 	}
+	if client.BaseURL != defaultEndpoint {
+		t.Errorf("NewClient() BaseURL = %s, want %s", client.BaseURL, defaultEndpoint)
+	}
 }
 
-func TestListKnowledge_Mock(t *testing.T) {
-	client := NewClient("test_api_key")
-	response, err := client.ListKnowledge()
-	if err != nil {
-		t.Fatalf("ListKnowledge() error = %v", err)
+func TestNewClient_Endpoint(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		want     string
+		wantErr  bool
+	}{
+		{"https://devin.example.com/api/v1/", "https://devin.example.com/api/v1", false},
+		{"http://127.0.0.1:8080", "http://127.0.0.1:8080", false},
+		{"devin.example.com/v1", "", true},
+		{"ftp://devin.example.com", "", true},
+		{"https://", "", true},
+		{"https://devin.example.com/v1?x=1", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.endpoint, func(t *testing.T) {
+			client, err := NewClient("key", tt.endpoint)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("NewClient(%q) should return an error", tt.endpoint)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewClient(%q) error = %v", tt.endpoint, err)
+			}
+			if client.BaseURL != tt.want {
+				t.Errorf("NewClient(%q) BaseURL = %s, want %s", tt.endpoint, client.BaseURL, tt.want)
+			}
+		})
 	}
+}
+
+func TestListKnowledge_ContextCancelsRequest(t *testing.T) {
+	client := newTestClient(t, "real-api-key")
+	client.HTTPClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		// Block until the request context is done, like a hung API call
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	})
 
-	if len(response.Knowledge) != 2 {
-		t.Errorf("ListKnowledge() returned %d items, want 2", len(response.Knowledge))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := client.ListKnowledge(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("ListKnowledge() error = %v, want context.DeadlineExceeded", err)
 	}
+}
 
-	if len(response.Folders) != 2 {
-		t.Errorf("ListKnowledge() returned %d folders, want 2", len(response.Folders))
+// newStubClient returns a client whose HTTP calls are served by the given
+// handler and whose retry backoff is short enough for tests
+func newStubClient(t *testing.T, handler roundTripFunc) *DevinClient {
+	client := newTestClient(t, "real-api-key")
+	client.HTTPClient.Transport = handler
+	client.retryMinWait = time.Millisecond
+	client.RetryMaxWait = 10 * time.Millisecond
+	return client
+}
+
+// stubResponse builds an HTTP response for stub transports
+func stubResponse(statusCode int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: statusCode,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader(body)),
 	}
+}
+
+func TestSendRequest_RetriesTransientErrors(t *testing.T) {
+	var calls int32
+	client := newStubClient(t, func(req *http.Request) (*http.Response, error) {
+		switch atomic.AddInt32(&calls, 1) {
+		case 1:
+			return stubResponse(http.StatusServiceUnavailable, ""), nil
+		case 2:
+			return nil, errors.New("connection reset by peer")
+		case 3:
+			resp := stubResponse(http.StatusTooManyRequests, `{"error":{"message":"slow down","type":"rate_limit"}}`)
+			resp.Header.Set("Retry-After", "0")
+			return resp, nil
+		default:
+			return stubResponse(http.StatusOK, `{"knowledge":[],"folders":[]}`), nil
+		}
+	})
 
-	// 最初のナレッジの検証
-	if response.Knowledge[0].ID != "mock-knowledge-1" {
-		t.Errorf("First knowledge ID = %s, want %s", response.Knowledge[0].ID, "mock-knowledge-1")
+	if _, err := client.ListKnowledge(context.Background()); err != nil {
+		t.Fatalf("ListKnowledge() error = %v", err)
 	}
-	if response.Knowledge[0].Name != "モックナレッジ1" {
-		t.Errorf("First knowledge Name = %s, want %s", response.Knowledge[0].Name, "モックナレッジ1")
+	if calls != 4 {
+		t.Errorf("ListKnowledge() made %d requests, want 4", calls)
 	}
+}
 
-	// 2番目のナレッジの検証
-	if response.Knowledge[1].ID != "mock-knowledge-2" {
-		t.Errorf("Second knowledge ID = %s, want %s", response.Knowledge[1].ID, "mock-knowledge-2")
+func TestSendRequest_GivesUpAfterMaxRetries(t *testing.T) {
+	var calls int32
+	client := newStubClient(t, func(req *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&calls, 1)
+		return stubResponse(http.StatusBadGateway, ""), nil
+	})
+	client.MaxRetries = 2
+
+	_, err := client.ListKnowledge(context.Background())
+	if err == nil {
+		t.Fatal("ListKnowledge() should return an error")
 	}
-	if response.Knowledge[1].Name != "モックナレッジ2" {
-		t.Errorf("Second knowledge Name = %s, want %s", response.Knowledge[1].Name, "モックナレッジ2")
+	if calls != 3 {
+		t.Errorf("ListKnowledge() made %d requests, want 3", calls)
 	}
+}
+
+func TestSendRequest_DoesNotRetryClientErrors(t *testing.T) {
+	var calls int32
+	client := newStubClient(t, func(req *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&calls, 1)
+		return stubResponse(http.StatusBadRequest, `{"error":{"message":"bad","type":"invalid_request"}}`), nil
+	})
 
-	// フォルダーの検証
-	if response.Folders[0].ID != "mock-folder-1" {
-		t.Errorf("First folder ID = %s, want %s", response.Folders[0].ID, "mock-folder-1")
+	if _, err := client.ListKnowledge(context.Background()); err == nil {
+		t.Fatal("ListKnowledge() should return an error")
 	}
-	if response.Folders[0].Name != "モックフォルダ1" {
-		t.Errorf("First folder Name = %s, want %s", response.Folders[0].Name, "モックフォルダ1")
+	if calls != 1 {
+		t.Errorf("ListKnowledge() made %d requests, want 1", calls)
 	}
 }
 
-func TestGetKnowledge_Mock(t *testing.T) {
-	client := NewClient("test_api_key")
+func TestSendRequest_PostRetryPolicy(t *testing.T) {
+	var calls int32
+	statuses := []int{http.StatusTooManyRequests, http.StatusServiceUnavailable}
+	client := newStubClient(t, func(req *http.Request) (*http.Response, error) {
+		n := atomic.AddInt32(&calls, 1)
+		if int(n) <= len(statuses) {
+			return stubResponse(statuses[n-1], ""), nil
+		}
+		body, _ := io.ReadAll(req.Body)
+		return stubResponse(http.StatusOK, string(body)), nil
+	})
 
-	// 既存のIDの取得テスト
-	knowledge, err := client.GetKnowledge("mock-knowledge-1")
-	if err != nil {
-		t.Fatalf("GetKnowledge() error = %v", err)
+	// 429 is retried for POST, but 503 is not without opting in
+	if _, err := client.sendRequest(context.Background(), "POST", "/knowledge", CreateKnowledgeRequest{Name: "n"}); err == nil {
+		t.Fatal("sendRequest() should fail on 503 for POST")
+	}
+	if calls != 2 {
+		t.Errorf("sendRequest() made %d requests, want 2", calls)
 	}
 
-	if knowledge.ID != "mock-knowledge-1" {
-		t.Errorf("GetKnowledge() ID = %s, want %s", knowledge.ID, "mock-knowledge-1")
+	// With the opt-in, 5xx is retried and the body is resent intact
+	atomic.StoreInt32(&calls, 0)
+	respBody, err := client.sendRequest(context.Background(), "POST", "/knowledge", CreateKnowledgeRequest{Name: "n"}, withNonIdempotentRetry())
+	if err != nil {
+		t.Fatalf("sendRequest() error = %v", err)
 	}
-	if knowledge.Name != "モックナレッジ1" {
-		t.Errorf("GetKnowledge() Name = %s, want %s", knowledge.Name, "モックナレッジ1")
+	if calls != 3 {
+		t.Errorf("sendRequest() made %d requests, want 3", calls)
 	}
-	if knowledge.Body != "これはテスト用のモックナレッジです" {
-		t.Errorf("GetKnowledge() Body = %s, want %s", knowledge.Body, "これはテスト用のモックナレッジです")
+	if !strings.Contains(string(respBody), `"name":"n"`) {
+		t.Errorf("sendRequest() resent body = %s, want the original request body", respBody)
 	}
-	if knowledge.TriggerDescription != "テスト用トリガーの説明" {
-		t.Errorf("GetKnowledge() TriggerDescription = %s, want %s", knowledge.TriggerDescription, "テスト用トリガーの説明")
+}
+
+func TestRetryAfterFromHeader(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		header http.Header
+		want   time.Duration
+		wantOK bool
+	}{
+		{"none", http.Header{}, 0, false},
+		{"seconds", http.Header{"Retry-After": {"7"}}, 7 * time.Second, true},
+		{"http date", http.Header{"Retry-After": {now.Add(3 * time.Second).Format(http.TimeFormat)}}, 3 * time.Second, true},
+		{"rate limit delta", http.Header{"X-Ratelimit-Remaining": {"0"}, "X-Ratelimit-Reset": {"5"}}, 5 * time.Second, true},
+		{"rate limit epoch", http.Header{"Ratelimit-Remaining": {"0"}, "Ratelimit-Reset": {strconv.FormatInt(now.Add(9*time.Second).Unix(), 10)}}, 9 * time.Second, true},
+		{"rate limit not exhausted", http.Header{"X-Ratelimit-Remaining": {"4"}, "X-Ratelimit-Reset": {"5"}}, 0, false},
 	}
-	if knowledge.ParentFolderID != "mock-folder-1" {
-		t.Errorf("GetKnowledge() ParentFolderID = %s, want %s", knowledge.ParentFolderID, "mock-folder-1")
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := retryAfterFromHeader(tt.header, now)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("retryAfterFromHeader() = (%s, %t), want (%s, %t)", got, ok, tt.want, tt.wantOK)
+			}
+		})
 	}
+}
 
-	// 存在しないIDのテスト
-	_, err = client.GetKnowledge("non-existent-id")
-	if err == nil {
-		t.Errorf("GetKnowledge() with non-existent ID should return error")
+func TestBackoff_CappedAtRetryMaxWait(t *testing.T) {
+	client := newTestClient(t, "real-api-key")
+	client.RetryMaxWait = 5 * time.Second
+
+	for attempt := 0; attempt < 10; attempt++ {
+		if wait := client.backoff(attempt); wait > client.RetryMaxWait {
+			t.Errorf("backoff(%d) = %s, want at most %s", attempt, wait, client.RetryMaxWait)
+		}
 	}
 }
 
-func TestCreateKnowledge_Mock(t *testing.T) {
-	client := NewClient("test_api_key")
-	knowledge, err := client.CreateKnowledge("テストナレッジ", "テスト内容", "テストトリガー", "test-folder-id")
+func TestClient_CustomEndpoint(t *testing.T) {
+	var listCalls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer server-key" {
+			t.Errorf("Authorization header = %q, want %q", got, "Bearer server-key")
+		}
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/knowledge":
+			// Fail the first list call to exercise retries against the custom host
+			if atomic.AddInt32(&listCalls, 1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			fmt.Fprint(w, `{"knowledge":[{"id":"k1","name":"Knowledge 1","parent_folder_id":"f1"}],"folders":[{"id":"f1","name":"Folder 1"}]}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/knowledge":
+			fmt.Fprint(w, `{"id":"k2","name":"Knowledge 2"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient("server-key", server.URL+"/v1/")
 	if err != nil {
-		t.Fatalf("CreateKnowledge() error = %v", err)
+		t.Fatalf("NewClient() error = %v", err)
 	}
+	client.retryMinWait = time.Millisecond
 
-	if knowledge.ID != "new-mock-knowledge" {
-		t.Errorf("CreateKnowledge() ID = %s, want %s", knowledge.ID, "new-mock-knowledge")
+	ctx := context.Background()
+	knowledge, err := client.GetKnowledge(ctx, "k1")
+	if err != nil {
+		t.Fatalf("GetKnowledge() error = %v", err)
+	}
+	if knowledge.Name != "Knowledge 1" {
+		t.Errorf("GetKnowledge() Name = %s, want %s", knowledge.Name, "Knowledge 1")
 	}
-	if knowledge.Name != "テストナレッジ" {
-		t.Errorf("CreateKnowledge() Name = %s, want %s", knowledge.Name, "テストナレッジ")
+
+	// Served from cache, no additional list request
+	if _, err := client.GetFolderByID(ctx, "f1"); err != nil {
+		t.Fatalf("GetFolderByID() error = %v", err)
 	}
-	if knowledge.Body != "テスト内容" {
-		t.Errorf("CreateKnowledge() Body = %s, want %s", knowledge.Body, "テスト内容")
+	if listCalls != 2 {
+		t.Errorf("list endpoint called %d times, want 2", listCalls)
 	}
-	if knowledge.TriggerDescription != "テストトリガー" {
-		t.Errorf("CreateKnowledge() TriggerDescription = %s, want %s", knowledge.TriggerDescription, "テストトリガー")
+
+	created, err := client.CreateKnowledge(ctx, "Knowledge 2", "body", "trigger", "")
+	if err != nil {
+		t.Fatalf("CreateKnowledge() error = %v", err)
 	}
-	if knowledge.ParentFolderID != "test-folder-id" {
-		t.Errorf("CreateKnowledge() ParentFolderID = %s, want %s", knowledge.ParentFolderID, "test-folder-id")
+	if created.ID != "k2" {
+		t.Errorf("CreateKnowledge() ID = %s, want %s", created.ID, "k2")
 	}
 }
 
-func TestUpdateKnowledge_Mock(t *testing.T) {
-	client := NewClient("test_api_key")
-	knowledge, err := client.UpdateKnowledge("mock-knowledge-1", "更新ナレッジ", "更新内容", "更新トリガー", "updated-folder-id")
-	if err != nil {
-		t.Fatalf("UpdateKnowledge() error = %v", err)
+func TestAPIError_Classification(t *testing.T) {
+	tests := []struct {
+		statusCode int
+		sentinel   error
+	}{
+		{http.StatusNotFound, ErrNotFound},
+		{http.StatusUnauthorized, ErrUnauthorized},
+		{http.StatusForbidden, ErrForbidden},
+		{http.StatusTooManyRequests, ErrRateLimited},
+		{http.StatusInternalServerError, ErrServerError},
+	}
+	sentinels := []error{ErrNotFound, ErrUnauthorized, ErrForbidden, ErrRateLimited, ErrServerError}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.statusCode), func(t *testing.T) {
+			client := newStubClient(t, func(req *http.Request) (*http.Response, error) {
+				resp := stubResponse(tt.statusCode, `{"error":{"message":"something failed","type":"api_error"}}`)
+				resp.Header.Set("X-Request-Id", "req-123")
+				return resp, nil
+			})
+			client.MaxRetries = 0
+
+			err := client.DeleteKnowledge(context.Background(), "k1")
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("DeleteKnowledge() error = %v, want *APIError", err)
+			}
+			if apiErr.StatusCode != tt.statusCode {
+				t.Errorf("APIError.StatusCode = %d, want %d", apiErr.StatusCode, tt.statusCode)
+			}
+			if apiErr.Type != "api_error" || apiErr.Message != "something failed" {
+				t.Errorf("APIError type/message = %q/%q, want %q/%q", apiErr.Type, apiErr.Message, "api_error", "something failed")
+			}
+			if apiErr.RequestID != "req-123" {
+				t.Errorf("APIError.RequestID = %q, want %q", apiErr.RequestID, "req-123")
+			}
+			if len(apiErr.Body) == 0 {
+				t.Error("APIError.Body is empty")
+			}
+
+			for _, sentinel := range sentinels {
+				if got, want := errors.Is(err, sentinel), sentinel == tt.sentinel; got != want {
+					t.Errorf("errors.Is(err, %v) = %t, want %t", sentinel, got, want)
+				}
+			}
+		})
 	}
+}
 
-	if knowledge.ID != "mock-knowledge-1" {
-		t.Errorf("UpdateKnowledge() ID = %s, want %s", knowledge.ID, "mock-knowledge-1")
+func TestGetKnowledge_NotFoundInList(t *testing.T) {
+	client := newStubClient(t, func(req *http.Request) (*http.Response, error) {
+		return stubResponse(http.StatusOK, `{"knowledge":[],"folders":[]}`), nil
+	})
+
+	_, err := client.GetKnowledge(context.Background(), "missing")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetKnowledge() error = %v, want ErrNotFound", err)
+	}
+	_, _, err = client.GetFolderByName(context.Background(), "missing")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetFolderByName() error = %v, want ErrNotFound", err)
 	}
-	if knowledge.Name != "更新ナレッジ" {
-		t.Errorf("UpdateKnowledge() Name = %s, want %s", knowledge.Name, "更新ナレッジ")
+}
+
+func TestSendRequest_ClientSideRateLimit(t *testing.T) {
+	client := newStubClient(t, func(req *http.Request) (*http.Response, error) {
+		return stubResponse(http.StatusOK, `{}`), nil
+	})
+	client.SetRateLimit(50, 1)
+
+	// One token is available immediately, the other four are spaced 20ms apart
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if err := client.DeleteKnowledge(context.Background(), "k1"); err != nil {
+			t.Fatalf("DeleteKnowledge() error = %v", err)
+		}
 	}
-	if knowledge.Body != "更新内容" {
-		t.Errorf("UpdateKnowledge() Body = %s, want %s", knowledge.Body, "更新内容")
+	if elapsed := time.Since(start); elapsed < 70*time.Millisecond {
+		t.Errorf("5 requests at 50 req/s took %s, want at least 70ms", elapsed)
 	}
-	if knowledge.TriggerDescription != "更新トリガー" {
-		t.Errorf("UpdateKnowledge() TriggerDescription = %s, want %s", knowledge.TriggerDescription, "更新トリガー")
+
+	// A cancelled context stops waiting for a token
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := client.DeleteKnowledge(ctx, "k1"); err == nil {
+		t.Error("DeleteKnowledge() with cancelled context should return an error")
 	}
-	if knowledge.ParentFolderID != "updated-folder-id" {
-		t.Errorf("UpdateKnowledge() ParentFolderID = %s, want %s", knowledge.ParentFolderID, "updated-folder-id")
+}
+
+func TestUserAgent(t *testing.T) {
+	tests := []struct {
+		version, terraformVersion, suffix string
+		want                              string
+	}{
+		{"0.0.7", "1.9.5", "", "terraform-provider-devin/0.0.7 (+terraform 1.9.5)"},
+		{"0.0.7", "1.9.5", " team-a ", "terraform-provider-devin/0.0.7 (+terraform 1.9.5) team-a"},
+		{"", "", "", "terraform-provider-devin/dev"},
+	}
+	for _, tt := range tests {
+		if got := userAgent(tt.version, tt.terraformVersion, tt.suffix); got != tt.want {
+			t.Errorf("userAgent(%q, %q, %q) = %q, want %q", tt.version, tt.terraformVersion, tt.suffix, got, tt.want)
+		}
 	}
 }
 
-func TestDeleteKnowledge_Mock(t *testing.T) {
-	client := NewClient("test_api_key")
-	err := client.DeleteKnowledge("mock-knowledge-1")
-	if err != nil {
-		t.Errorf("DeleteKnowledge() error = %v", err)
+func TestSendRequest_UserAgent(t *testing.T) {
+	var got string
+	client := newStubClient(t, func(req *http.Request) (*http.Response, error) {
+		got = req.Header.Get("User-Agent")
+		return stubResponse(http.StatusOK, `{"knowledge":[],"folders":[]}`), nil
+	})
+	client.UserAgent = userAgent("1.2.3", "1.9.5", "pipeline-x")
+
+	if _, err := client.ListKnowledge(context.Background()); err != nil {
+		t.Fatalf("ListKnowledge() error = %v", err)
+	}
+	if want := "terraform-provider-devin/1.2.3 (+terraform 1.9.5) pipeline-x"; got != want {
+		t.Errorf("User-Agent = %q, want %q", got, want)
 	}
 }
@@ -0,0 +1,230 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	// Bump when the on-disk format changes so old files are ignored
+	diskCacheVersion = 1
+	// Default lifetime of a persisted knowledge list
+	defaultDiskCacheTTL = 15 * time.Minute
+	// How long to wait for another process holding the lock
+	diskCacheLockTimeout = 10 * time.Second
+	// Locks older than this are assumed to belong to a crashed process
+	diskCacheStaleLockAge = 30 * time.Second
+	// Polling interval while waiting for the lock
+	diskCacheLockPoll = 50 * time.Millisecond
+)
+
+// diskCache persists the knowledge list so that separate provider processes
+// (plan, apply, remote state consumers) can share it. Writes are atomic
+// renames serialized by a lock file, and every entry carries a checksum.
+type diskCache struct {
+	path string
+	key  string
+	ttl  time.Duration
+}
+
+// diskCacheEntry is the JSON document stored on disk
+type diskCacheEntry struct {
+	Version   int             `json:"version"`
+	Key       string          `json:"key"`
+	FetchedAt time.Time       `json:"fetched_at"`
+	Checksum  string          `json:"checksum"`
+	Payload   json.RawMessage `json:"payload"`
+	// HTTP validators of the list, so a later process can revalidate it
+	Validators knowledgeValidators `json:"validators"`
+}
+
+// EnableDiskCache persists the knowledge list under dir, shared by every
+// provider process using the same API key and endpoint
+func (c *DevinClient) EnableDiskCache(dir string, ttl time.Duration) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	// The file name must not reveal the API key, so key it by a hash
+	sum := sha256.Sum256([]byte(c.APIKey + "\x00" + c.BaseURL))
+	key := hex.EncodeToString(sum[:])
+
+	c.diskCache = &diskCache{
+		path: filepath.Join(dir, "knowledge-"+key[:16]+".json"),
+		key:  key,
+		ttl:  ttl,
+	}
+	return nil
+}
+
+// load returns the persisted knowledge list as a snapshot if it is present,
+// intact and fresh. The snapshot keeps the time of the original fetch, so
+// it does not outlive the TTL in memory.
+func (d *diskCache) load(ctx context.Context) (*knowledgeSnapshot, bool) {
+	data, err := os.ReadFile(d.path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			tflog.Warn(ctx, "Failed to read knowledge disk cache", map[string]interface{}{
+				"path":  d.path,
+				"error": err.Error(),
+			})
+		}
+		return nil, false
+	}
+
+	var entry diskCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || !d.valid(&entry) {
+		tflog.Warn(ctx, "Ignoring corrupt knowledge disk cache", map[string]interface{}{
+			"path": d.path,
+		})
+		d.invalidate(ctx)
+		return nil, false
+	}
+
+	if time.Since(entry.FetchedAt) >= d.ttl {
+		return nil, false
+	}
+
+	var response ListKnowledgeResponse
+	if err := json.Unmarshal(entry.Payload, &response); err != nil {
+		d.invalidate(ctx)
+		return nil, false
+	}
+
+	tflog.Debug(ctx, "Loaded knowledge list from disk cache", map[string]interface{}{
+		"path":       d.path,
+		"fetched_at": entry.FetchedAt.Format(time.RFC3339),
+	})
+	snap := newKnowledgeSnapshot(&response)
+	snap.validators = entry.Validators
+	snap.fetchedAt = entry.FetchedAt
+	return snap, true
+}
+
+// valid checks the entry's version, key and checksum
+func (d *diskCache) valid(entry *diskCacheEntry) bool {
+	if entry.Version != diskCacheVersion || entry.Key != d.key {
+		return false
+	}
+	sum := sha256.Sum256(entry.Payload)
+	return entry.Checksum == hex.EncodeToString(sum[:])
+}
+
+// save persists the snapshot's knowledge list unless current reports that the
+// data was superseded by a mutation; current is checked while holding the lock
+func (d *diskCache) save(ctx context.Context, snap *knowledgeSnapshot, current func() bool) {
+	payload, err := json.Marshal(snap.list)
+	if err != nil {
+		return
+	}
+	sum := sha256.Sum256(payload)
+	data, err := json.Marshal(diskCacheEntry{
+		Version:    diskCacheVersion,
+		Key:        d.key,
+		FetchedAt:  snap.fetchedAt,
+		Checksum:   hex.EncodeToString(sum[:]),
+		Payload:    payload,
+		Validators: snap.validators,
+	})
+	if err != nil {
+		return
+	}
+
+	err = d.withLock(ctx, func() error {
+		if !current() {
+			return nil
+		}
+		return writeFileAtomic(d.path, data)
+	})
+	if err != nil {
+		tflog.Warn(ctx, "Failed to write knowledge disk cache", map[string]interface{}{
+			"path":  d.path,
+			"error": err.Error(),
+		})
+	}
+}
+
+// invalidate removes the persisted knowledge list
+func (d *diskCache) invalidate(ctx context.Context) {
+	err := d.withLock(ctx, func() error {
+		if err := os.Remove(d.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		tflog.Warn(ctx, "Failed to invalidate knowledge disk cache", map[string]interface{}{
+			"path":  d.path,
+			"error": err.Error(),
+		})
+	}
+}
+
+// withLock runs fn while holding the cache's lock file
+func (d *diskCache) withLock(ctx context.Context, fn func() error) error {
+	return withFileLock(ctx, d.path, fn)
+}
+
+// withFileLock runs fn while holding the lock file of path. The lock is a
+// file created exclusively, which works the same way on every platform.
+func withFileLock(ctx context.Context, path string, fn func() error) error {
+	lockPath := path + ".lock"
+	deadline := time.Now().Add(diskCacheLockTimeout)
+
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			f.Close()
+			defer os.Remove(lockPath)
+			return fn()
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return fmt.Errorf("failed to create lock file: %w", err)
+		}
+
+		// Break locks left behind by a crashed process
+		if info, statErr := os.Stat(lockPath); statErr == nil && time.Since(info.ModTime()) > diskCacheStaleLockAge {
+			os.Remove(lockPath)
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for lock file %s", lockPath)
+		}
+		if err := sleepContext(ctx, diskCacheLockPoll); err != nil {
+			return err
+		}
+	}
+}
+
+// writeFileAtomic writes data to a temporary file and renames it into place,
+// so readers never see a partially written file
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,225 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newDiskCachedClient returns a stub client using dir as its disk cache and
+// counting the list requests it sends
+func newDiskCachedClient(t *testing.T, apiKey, dir string, listCalls *int32) *DevinClient {
+	t.Helper()
+	client := newStubClient(t, func(req *http.Request) (*http.Response, error) {
+		if req.Method == http.MethodGet {
+			atomic.AddInt32(listCalls, 1)
+			return stubResponse(http.StatusOK, `{"knowledge":[{"id":"k1","name":"Knowledge 1"}],"folders":[]}`), nil
+		}
+		return stubResponse(http.StatusOK, `{"id":"k2","name":"Knowledge 2"}`), nil
+	})
+	client.APIKey = apiKey
+	if err := client.EnableDiskCache(dir, time.Minute); err != nil {
+		t.Fatalf("EnableDiskCache() error = %v", err)
+	}
+	return client
+}
+
+func TestDiskCache_SharedBetweenClients(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	var listCalls int32
+
+	// "plan" populates the disk cache
+	plan := newDiskCachedClient(t, "key", dir, &listCalls)
+	if _, err := plan.ListKnowledge(ctx); err != nil {
+		t.Fatalf("ListKnowledge() error = %v", err)
+	}
+
+	// "apply" is a new process with an empty in-memory cache
+	apply := newDiskCachedClient(t, "key", dir, &listCalls)
+	knowledge, err := apply.GetKnowledge(ctx, "k1")
+	if err != nil {
+		t.Fatalf("GetKnowledge() error = %v", err)
+	}
+	if knowledge.Name != "Knowledge 1" {
+		t.Errorf("GetKnowledge() Name = %s, want Knowledge 1", knowledge.Name)
+	}
+	if listCalls != 1 {
+		t.Errorf("list endpoint called %d times, want 1", listCalls)
+	}
+
+	// A different API key must not see the cached list
+	other := newDiskCachedClient(t, "other-key", dir, &listCalls)
+	if _, err := other.ListKnowledge(ctx); err != nil {
+		t.Fatalf("ListKnowledge() error = %v", err)
+	}
+	if listCalls != 2 {
+		t.Errorf("list endpoint called %d times, want 2", listCalls)
+	}
+}
+
+// backdateDiskCache rewrites the persisted entry as if it had been fetched
+// age ago
+func backdateDiskCache(t *testing.T, client *DevinClient, age time.Duration) {
+	t.Helper()
+	data, err := os.ReadFile(client.diskCache.path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	var entry diskCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	entry.FetchedAt = time.Now().Add(-age)
+	if data, err = json.Marshal(entry); err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if err := os.WriteFile(client.diskCache.path, data, 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+}
+
+func TestDiskCache_KeepsFetchTime(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	var listCalls int32
+
+	plan := newDiskCachedClient(t, "key", dir, &listCalls)
+	if _, err := plan.ListKnowledge(ctx); err != nil {
+		t.Fatalf("ListKnowledge() error = %v", err)
+	}
+	backdateDiskCache(t, plan, 50*time.Second)
+
+	apply := newDiskCachedClient(t, "key", dir, &listCalls)
+	if _, err := apply.ListKnowledge(ctx); err != nil {
+		t.Fatalf("ListKnowledge() error = %v", err)
+	}
+	if n := atomic.LoadInt32(&listCalls); n != 1 {
+		t.Fatalf("list endpoint called %d times, want 1", n)
+	}
+	if _, age := apply.cachedKnowledge(); age < 50*time.Second {
+		t.Errorf("snapshot loaded from disk has age %v, want the age of the original fetch", age)
+	}
+}
+
+func TestDiskCache_OlderThanMemoryTTLIsRefetched(t *testing.T) {
+	for _, age := range []time.Duration{7 * time.Minute, 12 * time.Minute} {
+		t.Run(age.String(), func(t *testing.T) {
+			dir := t.TempDir()
+			ctx := context.Background()
+			var listCalls int32
+
+			plan := newDiskCachedClient(t, "key", dir, &listCalls)
+			if _, err := plan.ListKnowledge(ctx); err != nil {
+				t.Fatalf("ListKnowledge() error = %v", err)
+			}
+			// Still within the disk TTL, but stale or expired in memory
+			backdateDiskCache(t, plan, age)
+
+			apply := newDiskCachedClient(t, "key", dir, &listCalls)
+			apply.diskCache.ttl = 15 * time.Minute
+			for i := 0; i < 5; i++ {
+				if _, err := apply.GetKnowledge(ctx, "k1"); err != nil {
+					t.Fatalf("GetKnowledge() error = %v", err)
+				}
+			}
+
+			if usage := apply.Usage(); usage.CacheMisses != 1 || usage.CacheHits != 4 {
+				t.Errorf("usage = %+v, want 1 cache miss and 4 hits", usage)
+			}
+			if n := atomic.LoadInt32(&listCalls); n != 2 {
+				t.Errorf("list endpoint called %d times, want 2", n)
+			}
+		})
+	}
+}
+
+func TestDiskCache_InvalidatedByMutation(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	var listCalls int32
+
+	client := newDiskCachedClient(t, "key", dir, &listCalls)
+	if _, err := client.ListKnowledge(ctx); err != nil {
+		t.Fatalf("ListKnowledge() error = %v", err)
+	}
+	if _, err := os.Stat(client.diskCache.path); err != nil {
+		t.Fatalf("disk cache file not written: %v", err)
+	}
+
+	if _, err := client.CreateKnowledge(ctx, "Knowledge 2", "body", "trigger", ""); err != nil {
+		t.Fatalf("CreateKnowledge() error = %v", err)
+	}
+	if _, err := os.Stat(client.diskCache.path); !os.IsNotExist(err) {
+		t.Errorf("disk cache file should be removed after a mutation, stat error = %v", err)
+	}
+}
+
+func TestDiskCache_IgnoresCorruptAndExpiredEntries(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	var listCalls int32
+
+	client := newDiskCachedClient(t, "key", dir, &listCalls)
+	if _, err := client.ListKnowledge(ctx); err != nil {
+		t.Fatalf("ListKnowledge() error = %v", err)
+	}
+
+	// Tamper with the payload without updating the checksum
+	data, err := os.ReadFile(client.diskCache.path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	tampered := []byte(string(data[:len(data)-3]) + " }}")
+	if err := os.WriteFile(client.diskCache.path, tampered, 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if _, ok := client.diskCache.load(ctx); ok {
+		t.Error("load() should reject a corrupt entry")
+	}
+
+	// A fresh client refetches and rewrites the cache
+	fresh := newDiskCachedClient(t, "key", dir, &listCalls)
+	if _, err := fresh.ListKnowledge(ctx); err != nil {
+		t.Fatalf("ListKnowledge() error = %v", err)
+	}
+	if _, ok := fresh.diskCache.load(ctx); !ok {
+		t.Error("load() should accept the rewritten entry")
+	}
+
+	// Entries past their TTL are ignored
+	fresh.diskCache.ttl = 0
+	if _, ok := fresh.diskCache.load(ctx); ok {
+		t.Error("load() should reject an expired entry")
+	}
+}
+
+func TestDiskCache_BreaksStaleLock(t *testing.T) {
+	dir := t.TempDir()
+	d := &diskCache{path: filepath.Join(dir, "knowledge.json"), key: "k", ttl: time.Minute}
+
+	lockPath := d.path + ".lock"
+	if err := os.WriteFile(lockPath, nil, 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	old := time.Now().Add(-2 * diskCacheStaleLockAge)
+	if err := os.Chtimes(lockPath, old, old); err != nil {
+		t.Fatalf("Chtimes() error = %v", err)
+	}
+
+	ran := false
+	if err := d.withLock(context.Background(), func() error { ran = true; return nil }); err != nil {
+		t.Fatalf("withLock() error = %v", err)
+	}
+	if !ran {
+		t.Error("withLock() did not run the function")
+	}
+	if _, err := os.Stat(lockPath); !os.IsNotExist(err) {
+		t.Errorf("lock file should be released, stat error = %v", err)
+	}
+}
@@ -0,0 +1,146 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Sentinel errors for classifying Devin API failures with errors.Is
+var (
+	// ErrNotFound indicates that the requested resource does not exist
+	ErrNotFound = errors.New("resource not found")
+	// ErrUnauthorized indicates that the API key is missing or invalid
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden indicates that the API key lacks permission for the operation
+	ErrForbidden = errors.New("forbidden")
+	// ErrRateLimited indicates that the API rejected the request due to rate limiting
+	ErrRateLimited = errors.New("rate limited")
+	// ErrServerError indicates a failure on the Devin API side (5xx)
+	ErrServerError = errors.New("server error")
+	// ErrDuplicateKnowledgeName indicates that a knowledge name matches more than one item in a folder
+	ErrDuplicateKnowledgeName = errors.New("duplicate knowledge name")
+	// ErrCircuitOpen indicates that a request was not sent because the Devin API kept failing
+	ErrCircuitOpen = errors.New("circuit breaker open")
+)
+
+// Response headers that may carry a request ID for support tickets
+var requestIDHeaders = []string{"X-Request-Id", "Request-Id", "X-Correlation-Id"}
+
+// APIError represents an error response returned by the Devin API
+type APIError struct {
+	// HTTP status code of the response
+	StatusCode int
+	// Error type reported by the API, if any
+	Type string
+	// Error message reported by the API, if any
+	Message string
+	// Request ID from the response headers, if any
+	RequestID string
+	// Raw response body
+	Body []byte
+}
+
+// newAPIError builds an APIError from an error response
+func newAPIError(resp *http.Response, respBody []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Body:       respBody,
+	}
+
+	var errResp ErrorResponse
+	if err := json.Unmarshal(respBody, &errResp); err == nil {
+		apiErr.Type = errResp.Error.Type
+		apiErr.Message = errResp.Error.Message
+	}
+
+	apiErr.RequestID = requestIDFromHeader(resp.Header)
+
+	return apiErr
+}
+
+// requestIDFromHeader returns the request ID of a response, if any
+func requestIDFromHeader(header http.Header) string {
+	for _, name := range requestIDHeaders {
+		if v := header.Get(name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	var b strings.Builder
+	if e.Message != "" {
+		fmt.Fprintf(&b, "API error: %s", e.Message)
+		if e.Type != "" {
+			fmt.Fprintf(&b, " (%s)", e.Type)
+		}
+		fmt.Fprintf(&b, ", status code %d", e.StatusCode)
+	} else {
+		fmt.Fprintf(&b, "API error: status code %d", e.StatusCode)
+	}
+	if e.RequestID != "" {
+		fmt.Fprintf(&b, ", request ID %s", e.RequestID)
+	}
+	return b.String()
+}
+
+// Is matches the sentinel error corresponding to the status code
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrServerError:
+		return e.StatusCode >= 500
+	default:
+		return false
+	}
+}
+
+// notFoundError describes a resource missing from the knowledge list and matches ErrNotFound
+type notFoundError struct {
+	msg string
+}
+
+// Error implements the error interface
+func (e *notFoundError) Error() string {
+	return e.msg
+}
+
+// Is matches ErrNotFound
+func (e *notFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// apiErrorDetail builds a diagnostic detail message explaining a failed API call
+func apiErrorDetail(err error) string {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return fmt.Sprintf("The operation did not finish within its timeout. Increase the matching value in the resource's timeouts block if the Devin API is slow or rate limiting requests.\n\nError: %s", err)
+	case errors.Is(err, ErrUnauthorized):
+		return fmt.Sprintf("The Devin API rejected the API key. Check the api_key provider attribute or the DEVIN_API_KEY environment variable.\n\nError: %s", err)
+	case errors.Is(err, ErrForbidden):
+		return fmt.Sprintf("The API key does not have permission for this operation.\n\nError: %s", err)
+	case errors.Is(err, ErrNotFound):
+		return fmt.Sprintf("The requested resource does not exist in the Devin API.\n\nError: %s", err)
+	case errors.Is(err, ErrRateLimited):
+		return fmt.Sprintf("The Devin API is rate limiting requests. Consider increasing max_retries or retry_max_wait, or lowering Terraform's -parallelism.\n\nError: %s", err)
+	case errors.Is(err, ErrCircuitOpen):
+		return fmt.Sprintf("The Devin API keeps failing, so the provider stopped sending requests to it instead of waiting for each one to fail. A probe request is let through once circuit_breaker_cooldown has passed. Retry the apply once the API recovers.\n\nError: %s", err)
+	case errors.Is(err, ErrServerError):
+		return fmt.Sprintf("The Devin API returned a server error. Please try again later.\n\nError: %s", err)
+	default:
+		return fmt.Sprintf("Error during Devin API request: %s", err)
+	}
+}
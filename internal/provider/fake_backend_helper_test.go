@@ -0,0 +1,206 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// fakeBackend is an in-memory implementation of DevinAPI for tests. It keeps
+// the knowledge and folders it is given, generates IDs for created knowledge
+// and reports missing resources with ErrNotFound, like the real API. It is
+// safe for concurrent use.
+type fakeBackend struct {
+	mu        sync.RWMutex
+	knowledge []KnowledgeItem
+	folders   []FolderItem
+	nextID    int
+
+	// now returns the creation time of new resources
+	now func() time.Time
+}
+
+var _ DevinAPI = (*fakeBackend)(nil)
+
+// newFakeBackend returns an empty fake backend
+func newFakeBackend() *fakeBackend {
+	return &fakeBackend{now: time.Now}
+}
+
+// newFakeBackendFromState returns a fake backend holding the given knowledge
+// and folders, for example as previously returned by ListKnowledge
+func newFakeBackendFromState(state *ListKnowledgeResponse) *fakeBackend {
+	f := newFakeBackend()
+	f.knowledge = append([]KnowledgeItem{}, state.Knowledge...)
+	f.folders = append([]FolderItem{}, state.Folders...)
+	return f
+}
+
+// AddFolder creates a folder and returns it. The Devin API offers no folder
+// endpoints, so folders can only be created this way.
+func (f *fakeBackend) AddFolder(name, description string) FolderItem {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	folder := FolderItem{
+		ID:          f.newID("folder"),
+		Name:        name,
+		Description: description,
+		CreatedAt:   f.now(),
+	}
+	f.folders = append(f.folders, folder)
+	return folder
+}
+
+// newID returns an unused resource ID; f.mu must be held
+func (f *fakeBackend) newID(prefix string) string {
+	snap := f.snapshot()
+	for {
+		f.nextID++
+		id := fmt.Sprintf("%s-%d", prefix, f.nextID)
+		if snap.knowledgeByID[id] == nil && snap.folderByID[id] == nil {
+			return id
+		}
+	}
+}
+
+// snapshot indexes a copy of the current state; f.mu must be held
+func (f *fakeBackend) snapshot() *knowledgeSnapshot {
+	list := &ListKnowledgeResponse{
+		Knowledge: append([]KnowledgeItem{}, f.knowledge...),
+		Folders:   append([]FolderItem{}, f.folders...),
+	}
+	return newKnowledgeSnapshot(list)
+}
+
+// validate checks a create or update request the way the API does
+func (f *fakeBackend) validate(name, body, triggerDescription, parentFolderID string) error {
+	required := []struct{ field, value string }{
+		{"name", name},
+		{"body", body},
+		{"trigger_description", triggerDescription},
+	}
+	for _, r := range required {
+		if r.value == "" {
+			return &APIError{
+				StatusCode: http.StatusBadRequest,
+				Type:       "invalid_request_error",
+				Message:    fmt.Sprintf("%s is required", r.field),
+			}
+		}
+	}
+	if parentFolderID != "" {
+		if _, err := f.snapshot().getFolderByID(parentFolderID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListKnowledge implements DevinAPI
+func (f *fakeBackend) ListKnowledge(_ context.Context) (*ListKnowledgeResponse, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.snapshot().list, nil
+}
+
+// ListKnowledgeInFolder implements DevinAPI
+func (f *fakeBackend) ListKnowledgeInFolder(_ context.Context, folderID string) ([]KnowledgeItem, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.snapshot().knowledgeInFolder(folderID), nil
+}
+
+// GetKnowledge implements DevinAPI
+func (f *fakeBackend) GetKnowledge(_ context.Context, id string) (*Knowledge, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.snapshot().getKnowledge(id)
+}
+
+// CreateKnowledge implements DevinAPI
+func (f *fakeBackend) CreateKnowledge(_ context.Context, name, body string, triggerDescription string, parentFolderID string) (*Knowledge, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.validate(name, body, triggerDescription, parentFolderID); err != nil {
+		return nil, err
+	}
+
+	item := KnowledgeItem{
+		ID:                 f.newID("knowledge"),
+		Name:               name,
+		Body:               body,
+		TriggerDescription: triggerDescription,
+		ParentFolderID:     parentFolderID,
+		CreatedAt:          f.now(),
+	}
+	f.knowledge = append(f.knowledge, item)
+
+	knowledge := Knowledge(item)
+	return &knowledge, nil
+}
+
+// UpdateKnowledge implements DevinAPI
+func (f *fakeBackend) UpdateKnowledge(_ context.Context, id, name, body string, triggerDescription string, parentFolderID string) (*Knowledge, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i := range f.knowledge {
+		if f.knowledge[i].ID != id {
+			continue
+		}
+		if err := f.validate(name, body, triggerDescription, parentFolderID); err != nil {
+			return nil, err
+		}
+
+		item := &f.knowledge[i]
+		item.Name = name
+		item.Body = body
+		item.TriggerDescription = triggerDescription
+		item.ParentFolderID = parentFolderID
+
+		knowledge := Knowledge(*item)
+		return &knowledge, nil
+	}
+
+	return nil, &notFoundError{msg: fmt.Sprintf("knowledge resource with ID '%s' not found", id)}
+}
+
+// DeleteKnowledge implements DevinAPI
+func (f *fakeBackend) DeleteKnowledge(_ context.Context, id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i := range f.knowledge {
+		if f.knowledge[i].ID == id {
+			f.knowledge = append(f.knowledge[:i], f.knowledge[i+1:]...)
+			return nil
+		}
+	}
+
+	return &notFoundError{msg: fmt.Sprintf("knowledge resource with ID '%s' not found", id)}
+}
+
+// GetFolderByID implements DevinAPI
+func (f *fakeBackend) GetFolderByID(_ context.Context, id string) (*FolderItem, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.snapshot().getFolderByID(id)
+}
+
+// FindKnowledge implements DevinAPI
+func (f *fakeBackend) FindKnowledge(_ context.Context, name, parentFolderID string) (*Knowledge, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.snapshot().findKnowledge(name, parentFolderID)
+}
+
+// GetFolderByName implements DevinAPI
+func (f *fakeBackend) GetFolderByName(_ context.Context, name string) (*FolderItem, []string, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.snapshot().getFolderByName(name)
+}
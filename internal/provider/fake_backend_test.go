@@ -0,0 +1,147 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestFakeBackend_CRUD(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeBackend()
+	folder := fake.AddFolder("Folder", "description")
+
+	first, err := fake.CreateKnowledge(ctx, "First", "body", "trigger", folder.ID)
+	if err != nil {
+		t.Fatalf("CreateKnowledge() error = %v", err)
+	}
+	second, err := fake.CreateKnowledge(ctx, "Second", "body", "trigger", "")
+	if err != nil {
+		t.Fatalf("CreateKnowledge() error = %v", err)
+	}
+	if first.ID == second.ID {
+		t.Errorf("CreateKnowledge() returned duplicate ID %s", first.ID)
+	}
+	if first.CreatedAt.IsZero() {
+		t.Error("CreateKnowledge() did not set CreatedAt")
+	}
+
+	// Updates are visible to later reads
+	if _, err := fake.UpdateKnowledge(ctx, second.ID, "Renamed", "new body", "trigger", folder.ID); err != nil {
+		t.Fatalf("UpdateKnowledge() error = %v", err)
+	}
+	got, err := fake.GetKnowledge(ctx, second.ID)
+	if err != nil {
+		t.Fatalf("GetKnowledge() error = %v", err)
+	}
+	if got.Name != "Renamed" || got.Body != "new body" || got.ParentFolderID != folder.ID {
+		t.Errorf("GetKnowledge() = %+v, want the updated knowledge", got)
+	}
+
+	inFolder, err := fake.ListKnowledgeInFolder(ctx, folder.ID)
+	if err != nil {
+		t.Fatalf("ListKnowledgeInFolder() error = %v", err)
+	}
+	if len(inFolder) != 2 {
+		t.Errorf("ListKnowledgeInFolder() returned %d items, want 2", len(inFolder))
+	}
+
+	if err := fake.DeleteKnowledge(ctx, first.ID); err != nil {
+		t.Fatalf("DeleteKnowledge() error = %v", err)
+	}
+	if _, err := fake.GetKnowledge(ctx, first.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetKnowledge() after delete error = %v, want ErrNotFound", err)
+	}
+	if err := fake.DeleteKnowledge(ctx, first.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("DeleteKnowledge() twice error = %v, want ErrNotFound", err)
+	}
+	if _, err := fake.UpdateKnowledge(ctx, first.ID, "Name", "body", "trigger", ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("UpdateKnowledge() after delete error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestFakeBackend_Validation(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeBackend()
+
+	if _, err := fake.CreateKnowledge(ctx, "Name", "body", "trigger", "missing-folder"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("CreateKnowledge() with unknown folder error = %v, want ErrNotFound", err)
+	}
+
+	_, err := fake.CreateKnowledge(ctx, "", "body", "trigger", "")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 400 {
+		t.Errorf("CreateKnowledge() without a name error = %v, want a 400 APIError", err)
+	}
+}
+
+func TestFakeBackend_Folders(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeBackend()
+	folder := fake.AddFolder("Folder", "")
+
+	got, _, err := fake.GetFolderByName(ctx, "Folder")
+	if err != nil {
+		t.Fatalf("GetFolderByName() error = %v", err)
+	}
+	if got.ID != folder.ID {
+		t.Errorf("GetFolderByName() ID = %s, want %s", got.ID, folder.ID)
+	}
+
+	duplicate := fake.AddFolder("Folder", "")
+	got, duplicateIDs, err := fake.GetFolderByName(ctx, "Folder")
+	if err != nil || got.ID != folder.ID || len(duplicateIDs) != 2 || duplicateIDs[1] != duplicate.ID {
+		t.Errorf("GetFolderByName() with duplicate names = %v, %v, %v, want the first folder and both IDs", got, duplicateIDs, err)
+	}
+	if _, err := fake.GetFolderByID(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetFolderByID() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestFakeBackend_Concurrent(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeBackend()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			knowledge, err := fake.CreateKnowledge(ctx, fmt.Sprintf("Knowledge %d", i), "body", "trigger", "")
+			if err != nil {
+				t.Errorf("CreateKnowledge() error = %v", err)
+				return
+			}
+			if _, err := fake.GetKnowledge(ctx, knowledge.ID); err != nil {
+				t.Errorf("GetKnowledge() error = %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	list, _ := fake.ListKnowledge(ctx)
+	if len(list.Knowledge) != 20 {
+		t.Errorf("ListKnowledge() returned %d items, want 20", len(list.Knowledge))
+	}
+}
+
+func TestFakeBackend_FromState(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeBackendFromState(&ListKnowledgeResponse{
+		Knowledge: []KnowledgeItem{{ID: "knowledge-1", Name: "Existing"}},
+		Folders:   []FolderItem{{ID: "folder-2", Name: "Folder"}},
+	})
+
+	created, err := fake.CreateKnowledge(ctx, "New", "body", "trigger", "folder-2")
+	if err != nil {
+		t.Fatalf("CreateKnowledge() error = %v", err)
+	}
+	if created.ID == "knowledge-1" || created.ID == "folder-2" {
+		t.Errorf("CreateKnowledge() reused existing ID %s", created.ID)
+	}
+	if _, err := fake.GetKnowledge(ctx, "knowledge-1"); err != nil {
+		t.Errorf("GetKnowledge() error = %v, want the restored knowledge", err)
+	}
+}
@@ -2,17 +2,20 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 // FolderDataSource defines the type for folder data source
 type FolderDataSource struct {
-	client *DevinClient
+	client DevinAPI
 }
 
 // FolderDataSourceModel represents the schema structure for the Terraform data source
@@ -61,11 +64,11 @@ func (d *FolderDataSource) Configure(_ context.Context, req datasource.Configure
 		return
 	}
 
-	client, ok := req.ProviderData.(*DevinClient)
+	client, ok := req.ProviderData.(DevinAPI)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected data source configure type",
-			fmt.Sprintf("Expected *DevinClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected DevinAPI, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 		return
 	}
@@ -75,6 +78,13 @@ func (d *FolderDataSource) Configure(_ context.Context, req datasource.Configure
 
 // Read reads the folder information
 func (d *FolderDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	ctx, span := startSpan(ctx, "data.devin_folder.Read")
+	ctx, schemaWarnings := withSchemaWarnings(ctx)
+	defer func() {
+		addSchemaWarnings(schemaWarnings, &resp.Diagnostics)
+		endHandlerSpan(span, resp.Diagnostics)
+	}()
+
 	var config FolderDataSourceModel
 	diags := req.Config.Get(ctx, &config)
 	resp.Diagnostics.Append(diags...)
@@ -83,7 +93,9 @@ func (d *FolderDataSource) Read(ctx context.Context, req datasource.ReadRequest,
 	}
 
 	var folder *FolderItem
+	var duplicateIDs []string
 	var err error
+	var lookupAttr path.Path
 
 	// Check if we're searching by ID or name
 	if !config.ID.IsNull() {
@@ -93,7 +105,8 @@ func (d *FolderDataSource) Read(ctx context.Context, req datasource.ReadRequest,
 		})
 
 		// Get folder by ID
-		folder, err = d.client.GetFolderByID(folderID)
+		folder, err = d.client.GetFolderByID(ctx, folderID)
+		lookupAttr = path.Root("id")
 	} else if !config.Name.IsNull() {
 		folderName := config.Name.ValueString()
 		tflog.Info(ctx, "Starting folder data retrieval by name", map[string]interface{}{
@@ -101,7 +114,8 @@ func (d *FolderDataSource) Read(ctx context.Context, req datasource.ReadRequest,
 		})
 
 		// Get folder by name
-		folder, err = d.client.GetFolderByName(folderName)
+		folder, duplicateIDs, err = d.client.GetFolderByName(ctx, folderName)
+		lookupAttr = path.Root("name")
 	} else {
 		resp.Diagnostics.AddError(
 			"Missing required attributes",
@@ -110,14 +124,33 @@ func (d *FolderDataSource) Read(ctx context.Context, req datasource.ReadRequest,
 		return
 	}
 
-	if err != nil {
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrNotFound):
+		resp.Diagnostics.AddAttributeError(
+			lookupAttr,
+			"Folder not found",
+			err.Error(),
+		)
+		return
+	default:
 		resp.Diagnostics.AddError(
 			"Error retrieving folder",
-			err.Error(),
+			apiErrorDetail(err),
 		)
 		return
 	}
 
+	if len(duplicateIDs) > 0 {
+		// Keep the first match so existing configurations keep working
+		resp.Diagnostics.AddAttributeWarning(
+			lookupAttr,
+			"Ambiguous folder name",
+			fmt.Sprintf("%d folders are named '%s' (IDs: %s); using the first one, %s. Look the folder up by id to pick a specific one.",
+				len(duplicateIDs), folder.Name, strings.Join(duplicateIDs, ", "), folder.ID),
+		)
+	}
+
 	// Map the API response to the Terraform model
 	state := FolderDataSourceModel{
 		ID:          types.StringValue(folder.ID),
@@ -0,0 +1,52 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestFolderDataSourceRead_DuplicateNameWarns(t *testing.T) {
+	ctx := context.Background()
+	client := newStubClient(t, func(req *http.Request) (*http.Response, error) {
+		return stubResponse(http.StatusOK, `{"knowledge":[],"folders":[
+			{"id":"f1","name":"Shared","description":"first"},
+			{"id":"f2","name":"Shared","description":"second"}
+		]}`), nil
+	})
+	d := &FolderDataSource{client: client}
+
+	var schemaResp datasource.SchemaResponse
+	d.Schema(ctx, datasource.SchemaRequest{}, &schemaResp)
+	objectType := schemaResp.Schema.Type().TerraformType(ctx)
+	config := tfsdk.Config{
+		Schema: schemaResp.Schema,
+		Raw: tftypes.NewValue(objectType, map[string]tftypes.Value{
+			"id":          tftypes.NewValue(tftypes.String, nil),
+			"name":        tftypes.NewValue(tftypes.String, "Shared"),
+			"description": tftypes.NewValue(tftypes.String, nil),
+		}),
+	}
+
+	resp := datasource.ReadResponse{State: tfsdk.State{Schema: schemaResp.Schema, Raw: tftypes.NewValue(objectType, nil)}}
+	d.Read(ctx, datasource.ReadRequest{Config: config}, &resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Read() unexpected error: %v", resp.Diagnostics)
+	}
+
+	warnings := resp.Diagnostics.Warnings()
+	if len(warnings) != 1 || warnings[0].Summary() != "Ambiguous folder name" || !strings.Contains(warnings[0].Detail(), "f1, f2") {
+		t.Errorf("Read() warnings = %v, want an ambiguous folder name warning listing f1, f2", warnings)
+	}
+
+	var state FolderDataSourceModel
+	resp.State.Get(ctx, &state)
+	if state.ID.ValueString() != "f1" || state.Description.ValueString() != "first" {
+		t.Errorf("Read() state = %+v, want the first folder", state)
+	}
+}
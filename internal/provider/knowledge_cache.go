@@ -0,0 +1,431 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// knowledgeSnapshot is an immutable copy of the knowledge list together with
+// lookup indexes built once per fetch, so lookups do not scan the list
+type knowledgeSnapshot struct {
+	list *ListKnowledgeResponse
+
+	knowledgeByID map[string]*KnowledgeItem
+	folderByID    map[string]*FolderItem
+	// Folder names are not unique in the Devin API, so keep every match
+	foldersByName map[string][]*FolderItem
+	// Knowledge grouped by parent folder ID; "" holds knowledge at the root
+	knowledgeByFolder map[string][]*KnowledgeItem
+
+	// Validators the server sent with the list, used to revalidate it
+	validators knowledgeValidators
+	// When the list was fetched or last revalidated; a snapshot loaded from
+	// the disk cache keeps the time of the original fetch
+	fetchedAt time.Time
+}
+
+// knowledgeValidators are the HTTP cache validators of a knowledge list
+type knowledgeValidators struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// validatorsFromHeader reads the validators from a response header
+func validatorsFromHeader(header http.Header) knowledgeValidators {
+	return knowledgeValidators{
+		ETag:         header.Get("ETag"),
+		LastModified: header.Get("Last-Modified"),
+	}
+}
+
+// empty reports whether there is nothing to revalidate with
+func (v knowledgeValidators) empty() bool {
+	return v.ETag == "" && v.LastModified == ""
+}
+
+// requestOptions returns the headers making a request conditional
+func (v knowledgeValidators) requestOptions() []requestOption {
+	var opts []requestOption
+	if v.ETag != "" {
+		opts = append(opts, withHeader("If-None-Match", v.ETag))
+	}
+	if v.LastModified != "" {
+		opts = append(opts, withHeader("If-Modified-Since", v.LastModified))
+	}
+	return opts
+}
+
+// newKnowledgeSnapshot builds the lookup indexes for a knowledge list
+func newKnowledgeSnapshot(list *ListKnowledgeResponse) *knowledgeSnapshot {
+	snap := &knowledgeSnapshot{
+		list:              list,
+		knowledgeByID:     make(map[string]*KnowledgeItem, len(list.Knowledge)),
+		folderByID:        make(map[string]*FolderItem, len(list.Folders)),
+		foldersByName:     make(map[string][]*FolderItem, len(list.Folders)),
+		knowledgeByFolder: make(map[string][]*KnowledgeItem),
+	}
+
+	for i := range list.Knowledge {
+		item := &list.Knowledge[i]
+		snap.knowledgeByID[item.ID] = item
+		snap.knowledgeByFolder[item.ParentFolderID] = append(snap.knowledgeByFolder[item.ParentFolderID], item)
+	}
+	for i := range list.Folders {
+		folder := &list.Folders[i]
+		snap.folderByID[folder.ID] = folder
+		snap.foldersByName[folder.Name] = append(snap.foldersByName[folder.Name], folder)
+	}
+
+	return snap
+}
+
+// getKnowledge looks up a knowledge resource by ID
+func (s *knowledgeSnapshot) getKnowledge(id string) (*Knowledge, error) {
+	item, ok := s.knowledgeByID[id]
+	if !ok {
+		return nil, &notFoundError{msg: fmt.Sprintf("knowledge resource with ID '%s' not found", id)}
+	}
+	knowledge := Knowledge(*item)
+	return &knowledge, nil
+}
+
+// getFolderByID looks up a folder by ID
+func (s *knowledgeSnapshot) getFolderByID(id string) (*FolderItem, error) {
+	folder, ok := s.folderByID[id]
+	if !ok {
+		return nil, &notFoundError{msg: fmt.Sprintf("folder resource with ID '%s' not found", id)}
+	}
+	result := *folder
+	return &result, nil
+}
+
+// getFolderByName looks up a folder by name. When the name is ambiguous the
+// first matching folder is returned along with the IDs of all matches
+func (s *knowledgeSnapshot) getFolderByName(name string) (*FolderItem, []string, error) {
+	folders := s.foldersByName[name]
+	if len(folders) == 0 {
+		return nil, nil, &notFoundError{msg: fmt.Sprintf("folder resource with name '%s' not found", name)}
+	}
+
+	var duplicateIDs []string
+	if len(folders) > 1 {
+		duplicateIDs = make([]string, len(folders))
+		for i, folder := range folders {
+			duplicateIDs[i] = folder.ID
+		}
+	}
+	result := *folders[0]
+	return &result, duplicateIDs, nil
+}
+
+// findKnowledge looks up a knowledge resource by name within a folder, failing
+// if more than one item matches
+func (s *knowledgeSnapshot) findKnowledge(name, parentFolderID string) (*Knowledge, error) {
+	var matches []*KnowledgeItem
+	for _, item := range s.knowledgeByFolder[parentFolderID] {
+		if item.Name == name {
+			matches = append(matches, item)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, &notFoundError{msg: fmt.Sprintf("knowledge resource with name '%s' not found", name)}
+	case 1:
+		knowledge := Knowledge(*matches[0])
+		return &knowledge, nil
+	default:
+		ids := make([]string, len(matches))
+		for i, item := range matches {
+			ids[i] = item.ID
+		}
+		return nil, fmt.Errorf("%w: %d knowledge resources are named '%s' (IDs: %s); import the one to manage instead",
+			ErrDuplicateKnowledgeName, len(matches), name, strings.Join(ids, ", "))
+	}
+}
+
+// knowledgeInFolder returns copies of the knowledge items in a folder
+func (s *knowledgeSnapshot) knowledgeInFolder(folderID string) []KnowledgeItem {
+	items := s.knowledgeByFolder[folderID]
+	result := make([]KnowledgeItem, len(items))
+	for i, item := range items {
+		result[i] = *item
+	}
+	return result
+}
+
+// duplicateFolderNames returns the folder names shared by more than one folder
+func (s *knowledgeSnapshot) duplicateFolderNames() []string {
+	var names []string
+	for name, folders := range s.foldersByName {
+		if len(folders) > 1 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// knowledgeRefresh is an in-flight knowledge list fetch shared by every
+// caller that needs a fresh snapshot at the same time
+type knowledgeRefresh struct {
+	// done is closed once snap and err are set
+	done chan struct{}
+	snap *knowledgeSnapshot
+	err  error
+
+	// Callers currently waiting on the result; protected by knowledgeRefreshMu
+	waiters int
+	// background refreshes keep running even when nobody is waiting
+	background bool
+	cancel     context.CancelFunc
+}
+
+// InvalidateCache clears the knowledge cache, including the disk cache if enabled
+func (c *DevinClient) InvalidateCache(ctx context.Context) {
+	c.knowledgeCacheMu.Lock()
+	c.knowledgeCache = nil
+	c.knowledgeCacheTime = time.Time{}
+	// Results of fetches started before now must not repopulate the cache
+	c.knowledgeCacheGen++
+	c.knowledgeCacheMu.Unlock()
+
+	if c.diskCache != nil {
+		c.diskCache.invalidate(ctx)
+	}
+}
+
+// isCurrentGeneration reports whether the cache was not invalidated or
+// mutated since gen was read
+func (c *DevinClient) isCurrentGeneration(gen uint64) bool {
+	c.knowledgeCacheMu.RLock()
+	defer c.knowledgeCacheMu.RUnlock()
+	return c.knowledgeCacheGen == gen
+}
+
+// cachedKnowledge returns the current snapshot and its age
+func (c *DevinClient) cachedKnowledge() (*knowledgeSnapshot, time.Duration) {
+	c.knowledgeCacheMu.RLock()
+	defer c.knowledgeCacheMu.RUnlock()
+	if c.knowledgeCache == nil {
+		return nil, 0
+	}
+	return c.knowledgeCache, time.Since(c.knowledgeCacheTime)
+}
+
+// knowledgeFromCache serves knowledge lookups from the cache.
+// Fresh snapshots are returned directly. Snapshots past CacheTTL but within
+// CacheStaleTTL are returned immediately while a single background refresh
+// runs. Otherwise the caller waits for a coalesced refresh.
+func (c *DevinClient) knowledgeFromCache(ctx context.Context) (*knowledgeSnapshot, error) {
+	cached, age := c.cachedKnowledge()
+	if cached != nil {
+		if age < c.CacheTTL {
+			c.usage.cacheHits.Add(1)
+			recordCacheResult(ctx, "hit")
+			return cached, nil
+		}
+		if age < c.CacheTTL+c.CacheStaleTTL {
+			c.usage.cacheHits.Add(1)
+			recordCacheResult(ctx, "stale")
+			c.startKnowledgeRefresh(ctx, true)
+			return cached, nil
+		}
+	}
+
+	c.usage.cacheMisses.Add(1)
+	recordCacheResult(ctx, "miss")
+	refresh := c.startKnowledgeRefresh(ctx, false)
+	return c.waitKnowledgeRefresh(ctx, refresh)
+}
+
+// startKnowledgeRefresh joins the in-flight refresh or starts a new one.
+// The fetch runs detached from ctx so that one caller giving up does not
+// fail the others; it is cancelled once every waiter has gone away.
+func (c *DevinClient) startKnowledgeRefresh(ctx context.Context, background bool) *knowledgeRefresh {
+	c.knowledgeRefreshMu.Lock()
+	defer c.knowledgeRefreshMu.Unlock()
+
+	if refresh := c.knowledgeRefresh; refresh != nil {
+		if background {
+			refresh.background = true
+		} else {
+			refresh.waiters++
+		}
+		return refresh
+	}
+
+	fetchCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	refresh := &knowledgeRefresh{
+		done:       make(chan struct{}),
+		background: background,
+		cancel:     cancel,
+	}
+	if !background {
+		refresh.waiters = 1
+	}
+	c.knowledgeRefresh = refresh
+
+	// The current snapshot, even if expired, can be revalidated instead of
+	// downloaded again
+	c.knowledgeCacheMu.RLock()
+	gen := c.knowledgeCacheGen
+	prev := c.knowledgeCache
+	c.knowledgeCacheMu.RUnlock()
+
+	go func() {
+		defer cancel()
+
+		snap, err := c.fetchKnowledgeList(fetchCtx, gen, prev)
+		if err == nil {
+			if duplicates := snap.duplicateFolderNames(); (prev == nil || snap.list != prev.list) && len(duplicates) > 0 {
+				tflog.Warn(fetchCtx, "Multiple Devin folders share the same name; look them up by ID instead of name", map[string]interface{}{
+					"folder_names": duplicates,
+				})
+			}
+			c.storeKnowledgeCache(snap, gen)
+		} else if background {
+			tflog.Warn(fetchCtx, "Background knowledge cache refresh failed, keeping stale snapshot", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+
+		c.knowledgeRefreshMu.Lock()
+		if c.knowledgeRefresh == refresh {
+			c.knowledgeRefresh = nil
+		}
+		c.knowledgeRefreshMu.Unlock()
+
+		refresh.snap, refresh.err = snap, err
+		close(refresh.done)
+	}()
+
+	return refresh
+}
+
+// waitKnowledgeRefresh waits for the refresh result or for ctx to be done
+func (c *DevinClient) waitKnowledgeRefresh(ctx context.Context, refresh *knowledgeRefresh) (*knowledgeSnapshot, error) {
+	select {
+	case <-refresh.done:
+		return refresh.snap, refresh.err
+	case <-ctx.Done():
+	}
+
+	c.knowledgeRefreshMu.Lock()
+	refresh.waiters--
+	if refresh.waiters == 0 && !refresh.background {
+		// Nobody needs the result anymore: cancel the request and let the
+		// next caller start over instead of joining a cancelled fetch
+		refresh.cancel()
+		if c.knowledgeRefresh == refresh {
+			c.knowledgeRefresh = nil
+		}
+	}
+	c.knowledgeRefreshMu.Unlock()
+
+	return nil, ctx.Err()
+}
+
+// storeKnowledgeCache replaces the snapshot unless the cache was invalidated
+// after the fetch started
+func (c *DevinClient) storeKnowledgeCache(snap *knowledgeSnapshot, gen uint64) {
+	c.knowledgeCacheMu.Lock()
+	defer c.knowledgeCacheMu.Unlock()
+	if c.knowledgeCacheGen != gen {
+		return
+	}
+	c.knowledgeCache = snap
+	c.knowledgeCacheTime = snap.fetchedAt
+}
+
+// mergeKnowledgeResponse converts a create/update response into a cache
+// entry, filling in fields the response omitted from the request
+func mergeKnowledgeResponse(knowledge Knowledge, req CreateKnowledgeRequest) KnowledgeItem {
+	item := KnowledgeItem(knowledge)
+	if item.Name == "" {
+		item.Name = req.Name
+	}
+	if item.Body == "" {
+		item.Body = req.Body
+	}
+	if item.TriggerDescription == "" {
+		item.TriggerDescription = req.TriggerDescription
+	}
+	if item.ParentFolderID == "" {
+		item.ParentFolderID = req.ParentFolderID
+	}
+	return item
+}
+
+// cacheKnowledgeUpsert writes a created or updated knowledge item into the
+// cached snapshot so that later reads see it without refetching the list
+func (c *DevinClient) cacheKnowledgeUpsert(ctx context.Context, item KnowledgeItem) {
+	c.updateKnowledgeCache(ctx, func(knowledge []KnowledgeItem) []KnowledgeItem {
+		for i := range knowledge {
+			if knowledge[i].ID == item.ID {
+				if item.CreatedAt.IsZero() {
+					item.CreatedAt = knowledge[i].CreatedAt
+				}
+				knowledge[i] = item
+				return knowledge
+			}
+		}
+		return append(knowledge, item)
+	})
+}
+
+// cacheKnowledgeDelete removes a knowledge item from the cached snapshot
+func (c *DevinClient) cacheKnowledgeDelete(ctx context.Context, id string) {
+	c.updateKnowledgeCache(ctx, func(knowledge []KnowledgeItem) []KnowledgeItem {
+		for i := range knowledge {
+			if knowledge[i].ID == id {
+				return append(knowledge[:i], knowledge[i+1:]...)
+			}
+		}
+		return knowledge
+	})
+}
+
+// updateKnowledgeCache replaces the cached snapshot with a modified copy.
+// Snapshots are shared with readers, so the knowledge slice is copied before
+// being passed to modify. The snapshot keeps its fetch time, so it still
+// expires on the usual TTL. The disk cache is invalidated, since other
+// processes cannot see the in-memory update.
+func (c *DevinClient) updateKnowledgeCache(ctx context.Context, modify func([]KnowledgeItem) []KnowledgeItem) {
+	c.writeThroughKnowledgeCache(modify)
+
+	if c.diskCache != nil {
+		c.diskCache.invalidate(ctx)
+	}
+}
+
+// writeThroughKnowledgeCache applies modify to the in-memory snapshot.
+// The new snapshot has no validators, since it no longer matches what the
+// server sent.
+func (c *DevinClient) writeThroughKnowledgeCache(modify func([]KnowledgeItem) []KnowledgeItem) {
+	c.knowledgeCacheMu.Lock()
+	defer c.knowledgeCacheMu.Unlock()
+
+	// A fetch started before this mutation would not include it
+	c.knowledgeCacheGen++
+
+	if c.knowledgeCache == nil {
+		return
+	}
+
+	old := c.knowledgeCache.list
+	knowledge := make([]KnowledgeItem, len(old.Knowledge), len(old.Knowledge)+1)
+	copy(knowledge, old.Knowledge)
+
+	fetchedAt := c.knowledgeCache.fetchedAt
+	c.knowledgeCache = newKnowledgeSnapshot(&ListKnowledgeResponse{
+		Knowledge: modify(knowledge),
+		Folders:   old.Folders,
+	})
+	c.knowledgeCache.fetchedAt = fetchedAt
+}
@@ -0,0 +1,642 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newCountingClient returns a stub client whose list endpoint reports the
+// number of fetches so far as the name of its only knowledge item. When
+// release is non-nil, each fetch signals entered (if non-nil) and then waits
+// for release to be closed.
+func newCountingClient(t testing.TB, fetches *int32, release <-chan struct{}, entered chan<- struct{}) *DevinClient {
+	client, err := NewClient("real-api-key", "")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	client.HTTPClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		n := atomic.AddInt32(fetches, 1)
+		if release != nil {
+			if entered != nil {
+				entered <- struct{}{}
+			}
+			select {
+			case <-release:
+			case <-req.Context().Done():
+				return nil, req.Context().Err()
+			}
+		}
+		body := `{"knowledge":[{"id":"k1","name":"fetch-` + string(rune('0'+n)) + `"}],"folders":[]}`
+		return stubResponse(http.StatusOK, body), nil
+	})
+	return client
+}
+
+// waitForRefreshWaiters blocks until n callers wait for the in-flight
+// knowledge list refresh
+func waitForRefreshWaiters(t *testing.T, client *DevinClient, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		client.knowledgeRefreshMu.Lock()
+		waiters := 0
+		if client.knowledgeRefresh != nil {
+			waiters = client.knowledgeRefresh.waiters
+		}
+		client.knowledgeRefreshMu.Unlock()
+		if waiters == n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d callers wait for the refresh, want %d", waiters, n)
+		}
+		runtime.Gosched()
+	}
+}
+
+// expireCache makes the current snapshot older than the given age
+func expireCache(client *DevinClient, age time.Duration) {
+	client.knowledgeCacheMu.Lock()
+	client.knowledgeCacheTime = time.Now().Add(-age)
+	client.knowledgeCacheMu.Unlock()
+}
+
+func TestListKnowledge_CoalescesConcurrentRefreshes(t *testing.T) {
+	var fetches int32
+	release := make(chan struct{})
+	entered := make(chan struct{}, 1)
+	client := newCountingClient(t, &fetches, release, entered)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 50)
+	list := func() {
+		defer wg.Done()
+		if _, err := client.ListKnowledge(context.Background()); err != nil {
+			errs <- err
+		}
+	}
+
+	// The first caller starts the fetch; every other caller joins it
+	// before it completes
+	wg.Add(1)
+	go list()
+	<-entered
+	for i := 1; i < 50; i++ {
+		wg.Add(1)
+		go list()
+	}
+	waitForRefreshWaiters(t, client, 50)
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("ListKnowledge() error = %v", err)
+	}
+	if n := atomic.LoadInt32(&fetches); n != 1 {
+		t.Errorf("50 concurrent ListKnowledge() calls made %d fetches, want 1", n)
+	}
+}
+
+func TestListKnowledge_StaleWhileRevalidate(t *testing.T) {
+	var fetches int32
+	client := newCountingClient(t, &fetches, nil, nil)
+	ctx := context.Background()
+
+	if _, err := client.ListKnowledge(ctx); err != nil {
+		t.Fatalf("ListKnowledge() error = %v", err)
+	}
+
+	// Past the TTL but within the stale window: the old snapshot is served
+	// immediately while a refresh runs in the background
+	expireCache(client, client.CacheTTL+time.Second)
+	resp, err := client.ListKnowledge(ctx)
+	if err != nil {
+		t.Fatalf("ListKnowledge() error = %v", err)
+	}
+	if resp.Knowledge[0].Name != "fetch-1" {
+		t.Errorf("ListKnowledge() = %s, want stale snapshot fetch-1", resp.Knowledge[0].Name)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		snap, _ := client.cachedKnowledge()
+		if snap != nil && snap.list.Knowledge[0].Name == "fetch-2" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("background refresh did not update the cache")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// Past the stale window the caller waits for a fresh snapshot
+	expireCache(client, client.CacheTTL+client.CacheStaleTTL+time.Second)
+	resp, err = client.ListKnowledge(ctx)
+	if err != nil {
+		t.Fatalf("ListKnowledge() error = %v", err)
+	}
+	if resp.Knowledge[0].Name != "fetch-3" {
+		t.Errorf("ListKnowledge() = %s, want fresh snapshot fetch-3", resp.Knowledge[0].Name)
+	}
+}
+
+func TestListKnowledge_ContextCancelsCacheWait(t *testing.T) {
+	var fetches int32
+	release := make(chan struct{})
+	defer close(release)
+	entered := make(chan struct{}, 1)
+	client := newCountingClient(t, &fetches, release, entered)
+
+	// A long-running refresh started by another caller
+	go client.ListKnowledge(context.Background())
+	<-entered
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := client.ListKnowledge(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("ListKnowledge() error = %v, want context.DeadlineExceeded", err)
+	}
+	if n := atomic.LoadInt32(&fetches); n != 1 {
+		t.Errorf("ListKnowledge() made %d fetches, want to join the in-flight one", n)
+	}
+}
+
+func TestInvalidateCache_DiscardsInFlightResult(t *testing.T) {
+	var fetches int32
+	release := make(chan struct{})
+	entered := make(chan struct{}, 1)
+	client := newCountingClient(t, &fetches, release, entered)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		client.ListKnowledge(context.Background())
+	}()
+	<-entered
+
+	// A mutation invalidates the cache while the fetch is in flight
+	client.InvalidateCache(context.Background())
+	close(release)
+	<-done
+
+	if snap, _ := client.cachedKnowledge(); snap != nil {
+		t.Error("a fetch started before InvalidateCache should not repopulate the cache")
+	}
+}
+
+// listKnowledgeLocked is the cache lookup used before refreshes were
+// coalesced, kept as a benchmark baseline: the cache lock is held for the
+// whole fetch, so every reader of an expired cache queues behind it
+func listKnowledgeLocked(ctx context.Context, c *DevinClient) (*ListKnowledgeResponse, error) {
+	if snap, age := c.cachedKnowledge(); snap != nil && age < c.CacheTTL {
+		return snap.list, nil
+	}
+
+	c.knowledgeCacheMu.Lock()
+	defer c.knowledgeCacheMu.Unlock()
+	if c.knowledgeCache != nil && time.Since(c.knowledgeCacheTime) < c.CacheTTL {
+		return c.knowledgeCache.list, nil
+	}
+	snap, err := c.fetchKnowledgeList(ctx, c.knowledgeCacheGen, c.knowledgeCache)
+	if err != nil {
+		return nil, err
+	}
+	c.knowledgeCache = snap
+	c.knowledgeCacheTime = snap.fetchedAt
+	return snap.list, nil
+}
+
+// BenchmarkListKnowledge_ConcurrentReaders measures 500 concurrent readers
+// hitting an expired cache while the API takes 5ms to respond. With
+// stale-while-revalidate readers return the previous snapshot without
+// waiting; past the stale window they all share a single fetch. The
+// lock-held-during-fetch baseline is the lookup these replaced.
+func BenchmarkListKnowledge_ConcurrentReaders(b *testing.B) {
+	const readers = 500
+
+	listKnowledge := func(ctx context.Context, c *DevinClient) (*ListKnowledgeResponse, error) {
+		return c.ListKnowledge(ctx)
+	}
+
+	for _, bc := range []struct {
+		name     string
+		staleTTL time.Duration
+		list     func(context.Context, *DevinClient) (*ListKnowledgeResponse, error)
+	}{
+		{"lock-held-during-fetch", 0, listKnowledgeLocked},
+		{"stale-while-revalidate", 5 * time.Minute, listKnowledge},
+		{"coalesced-refresh", 0, listKnowledge},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			var fetches int32
+			client := newCountingClient(b, &fetches, nil, nil)
+			client.HTTPClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				atomic.AddInt32(&fetches, 1)
+				time.Sleep(5 * time.Millisecond)
+				return stubResponse(http.StatusOK, `{"knowledge":[{"id":"k1"}],"folders":[]}`), nil
+			})
+			client.CacheStaleTTL = bc.staleTTL
+			ctx := context.Background()
+			if _, err := client.ListKnowledge(ctx); err != nil {
+				b.Fatalf("ListKnowledge() error = %v", err)
+			}
+			atomic.StoreInt32(&fetches, 0)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				expireCache(client, client.CacheTTL+time.Second)
+
+				var wg sync.WaitGroup
+				for r := 0; r < readers; r++ {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						if _, err := bc.list(ctx, client); err != nil {
+							b.Error(err)
+						}
+					}()
+				}
+				wg.Wait()
+			}
+			b.StopTimer()
+
+			// Wait for any background refresh to finish before reporting
+			for {
+				client.knowledgeRefreshMu.Lock()
+				idle := client.knowledgeRefresh == nil
+				client.knowledgeRefreshMu.Unlock()
+				if idle {
+					break
+				}
+				time.Sleep(time.Millisecond)
+			}
+			b.ReportMetric(float64(atomic.LoadInt32(&fetches))/float64(b.N), "fetches/op")
+		})
+	}
+}
+
+func TestKnowledgeSnapshot_Lookups(t *testing.T) {
+	client := newStubClient(t, func(req *http.Request) (*http.Response, error) {
+		return stubResponse(http.StatusOK, `{
+			"knowledge": [
+				{"id": "k1", "name": "Knowledge 1", "parent_folder_id": "f1"},
+				{"id": "k2", "name": "Knowledge 2", "parent_folder_id": "f1"},
+				{"id": "k3", "name": "Knowledge 3"},
+				{"id": "k4", "name": "Knowledge 1"},
+				{"id": "k5", "name": "Knowledge 3"}
+			],
+			"folders": [
+				{"id": "f1", "name": "Runbooks"},
+				{"id": "f2", "name": "Shared"},
+				{"id": "f3", "name": "Shared"}
+			]
+		}`), nil
+	})
+	ctx := context.Background()
+
+	knowledge, err := client.GetKnowledge(ctx, "k2")
+	if err != nil || knowledge.Name != "Knowledge 2" {
+		t.Errorf("GetKnowledge(k2) = %v, %v, want Knowledge 2", knowledge, err)
+	}
+
+	folder, err := client.GetFolderByID(ctx, "f2")
+	if err != nil || folder.Name != "Shared" {
+		t.Errorf("GetFolderByID(f2) = %v, %v, want Shared", folder, err)
+	}
+
+	folder, duplicateIDs, err := client.GetFolderByName(ctx, "Runbooks")
+	if err != nil || folder.ID != "f1" || duplicateIDs != nil {
+		t.Errorf("GetFolderByName(Runbooks) = %v, %v, %v, want f1", folder, duplicateIDs, err)
+	}
+
+	folder, duplicateIDs, err = client.GetFolderByName(ctx, "Shared")
+	if err != nil || folder.ID != "f2" || !reflect.DeepEqual(duplicateIDs, []string{"f2", "f3"}) {
+		t.Errorf("GetFolderByName(Shared) = %v, %v, %v, want the first match with duplicates f2, f3", folder, duplicateIDs, err)
+	}
+
+	items, err := client.ListKnowledgeInFolder(ctx, "f1")
+	if err != nil || len(items) != 2 {
+		t.Errorf("ListKnowledgeInFolder(f1) = %d items, %v, want 2", len(items), err)
+	}
+	items, err = client.ListKnowledgeInFolder(ctx, "")
+	if err != nil || len(items) != 3 || items[0].ID != "k3" {
+		t.Errorf("ListKnowledgeInFolder(\"\") = %v, %v, want [k3 k4 k5]", items, err)
+	}
+
+	// Names are matched within the folder only
+	knowledge, err = client.FindKnowledge(ctx, "Knowledge 1", "f1")
+	if err != nil || knowledge.ID != "k1" {
+		t.Errorf("FindKnowledge(Knowledge 1, f1) = %v, %v, want k1", knowledge, err)
+	}
+	knowledge, err = client.FindKnowledge(ctx, "Knowledge 1", "")
+	if err != nil || knowledge.ID != "k4" {
+		t.Errorf("FindKnowledge(Knowledge 1, \"\") = %v, %v, want k4", knowledge, err)
+	}
+	if _, err = client.FindKnowledge(ctx, "Knowledge 2", ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("FindKnowledge(Knowledge 2, \"\") error = %v, want ErrNotFound", err)
+	}
+	if _, err = client.FindKnowledge(ctx, "Knowledge 3", ""); !errors.Is(err, ErrDuplicateKnowledgeName) {
+		t.Errorf("FindKnowledge(Knowledge 3, \"\") error = %v, want ErrDuplicateKnowledgeName", err)
+	}
+
+	// Returned values are copies that cannot corrupt the cached snapshot
+	folder, _ = client.GetFolderByID(ctx, "f1")
+	folder.Name = "changed"
+	if folder, _ := client.GetFolderByID(ctx, "f1"); folder.Name != "Runbooks" {
+		t.Errorf("GetFolderByID(f1) Name = %s after modifying a previous result, want Runbooks", folder.Name)
+	}
+}
+
+func TestKnowledgeMutations_WriteThroughCache(t *testing.T) {
+	var listCalls int32
+	createResponse := `{"id":"k2","name":"Knowledge 2","body":"body 2","trigger_description":"trigger 2"}`
+	client := newStubClient(t, func(req *http.Request) (*http.Response, error) {
+		switch req.Method {
+		case http.MethodGet:
+			atomic.AddInt32(&listCalls, 1)
+			return stubResponse(http.StatusOK, `{"knowledge":[{"id":"k1","name":"Knowledge 1"}],"folders":[]}`), nil
+		case http.MethodPost:
+			return stubResponse(http.StatusOK, createResponse), nil
+		case http.MethodPut:
+			// Partial response: missing fields are taken from the request
+			return stubResponse(http.StatusOK, `{"id":"k1","name":"Renamed"}`), nil
+		default:
+			return stubResponse(http.StatusOK, `{}`), nil
+		}
+	})
+	ctx := context.Background()
+
+	if _, err := client.ListKnowledge(ctx); err != nil {
+		t.Fatalf("ListKnowledge() error = %v", err)
+	}
+
+	if _, err := client.CreateKnowledge(ctx, "Knowledge 2", "body 2", "trigger 2", "f1"); err != nil {
+		t.Fatalf("CreateKnowledge() error = %v", err)
+	}
+	created, err := client.GetKnowledge(ctx, "k2")
+	if err != nil {
+		t.Fatalf("GetKnowledge(k2) error = %v", err)
+	}
+	if created.Body != "body 2" || created.ParentFolderID != "f1" {
+		t.Errorf("GetKnowledge(k2) = %+v, want the created knowledge", created)
+	}
+
+	if _, err := client.UpdateKnowledge(ctx, "k1", "Renamed", "new body", "new trigger", ""); err != nil {
+		t.Fatalf("UpdateKnowledge() error = %v", err)
+	}
+	updated, err := client.GetKnowledge(ctx, "k1")
+	if err != nil {
+		t.Fatalf("GetKnowledge(k1) error = %v", err)
+	}
+	if updated.Name != "Renamed" || updated.Body != "new body" {
+		t.Errorf("GetKnowledge(k1) = %+v, want the updated knowledge", updated)
+	}
+
+	if err := client.DeleteKnowledge(ctx, "k2"); err != nil {
+		t.Fatalf("DeleteKnowledge() error = %v", err)
+	}
+	if _, err := client.GetKnowledge(ctx, "k2"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetKnowledge(k2) after delete error = %v, want ErrNotFound", err)
+	}
+
+	if n := atomic.LoadInt32(&listCalls); n != 1 {
+		t.Errorf("list endpoint called %d times, want 1", n)
+	}
+
+	// A response without an ID is ambiguous and forces a refetch
+	createResponse = `{}`
+	if _, err := client.CreateKnowledge(ctx, "Knowledge 3", "body 3", "trigger 3", ""); err != nil {
+		t.Fatalf("CreateKnowledge() error = %v", err)
+	}
+	if _, err := client.ListKnowledge(ctx); err != nil {
+		t.Fatalf("ListKnowledge() error = %v", err)
+	}
+	if n := atomic.LoadInt32(&listCalls); n != 2 {
+		t.Errorf("list endpoint called %d times after ambiguous create, want 2", n)
+	}
+}
+
+func TestKnowledgeMutations_DoNotModifyPreviousSnapshot(t *testing.T) {
+	client := newStubClient(t, func(req *http.Request) (*http.Response, error) {
+		if req.Method == http.MethodGet {
+			return stubResponse(http.StatusOK, `{"knowledge":[{"id":"k1","name":"Knowledge 1"},{"id":"k2","name":"Knowledge 2"}],"folders":[]}`), nil
+		}
+		return stubResponse(http.StatusOK, `{}`), nil
+	})
+	ctx := context.Background()
+
+	before, err := client.ListKnowledge(ctx)
+	if err != nil {
+		t.Fatalf("ListKnowledge() error = %v", err)
+	}
+	if err := client.DeleteKnowledge(ctx, "k1"); err != nil {
+		t.Fatalf("DeleteKnowledge() error = %v", err)
+	}
+
+	if len(before.Knowledge) != 2 || before.Knowledge[0].ID != "k1" {
+		t.Errorf("previously returned list changed to %+v", before.Knowledge)
+	}
+	after, _ := client.ListKnowledge(ctx)
+	if len(after.Knowledge) != 1 || after.Knowledge[0].ID != "k2" {
+		t.Errorf("ListKnowledge() after delete = %+v, want only k2", after.Knowledge)
+	}
+}
+
+func TestListKnowledge_ConditionalRevalidation(t *testing.T) {
+	const etag = `"v1"`
+	const lastModified = "Mon, 02 Jan 2006 15:04:05 GMT"
+	var full, notModified, unconditional int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			w.Write([]byte(`{"id":"k2","name":"Knowledge 2"}`))
+			return
+		}
+		if r.Header.Get("If-None-Match") == "" {
+			atomic.AddInt32(&unconditional, 1)
+		}
+		if r.Header.Get("If-None-Match") == etag && r.Header.Get("If-Modified-Since") == lastModified {
+			atomic.AddInt32(&notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		atomic.AddInt32(&full, 1)
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", lastModified)
+		w.Write([]byte(`{"knowledge":[{"id":"k1","name":"Knowledge 1"}],"folders":[]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := NewClient("key", server.URL)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	client.CacheStaleTTL = 0
+	ctx := context.Background()
+
+	first, err := client.knowledgeFromCache(ctx)
+	if err != nil {
+		t.Fatalf("knowledgeFromCache() error = %v", err)
+	}
+
+	// An expired snapshot is revalidated and reused on 304
+	expireCache(client, time.Hour)
+	second, err := client.knowledgeFromCache(ctx)
+	if err != nil {
+		t.Fatalf("knowledgeFromCache() error = %v", err)
+	}
+	if second.list != first.list {
+		t.Error("snapshot list should be reused after 304 Not Modified")
+	}
+	if _, age := client.cachedKnowledge(); age >= client.CacheTTL {
+		t.Errorf("revalidated snapshot age = %v, want fresh", age)
+	}
+	if full != 1 || notModified != 1 {
+		t.Errorf("full responses = %d, 304 responses = %d, want 1 and 1", full, notModified)
+	}
+
+	// A write-through snapshot no longer matches the server's version
+	if _, err := client.CreateKnowledge(ctx, "Knowledge 2", "body", "trigger", ""); err != nil {
+		t.Fatalf("CreateKnowledge() error = %v", err)
+	}
+	expireCache(client, time.Hour)
+	if _, err := client.knowledgeFromCache(ctx); err != nil {
+		t.Fatalf("knowledgeFromCache() error = %v", err)
+	}
+	if unconditional != 2 || full != 2 {
+		t.Errorf("unconditional requests = %d, full responses = %d, want 2 and 2", unconditional, full)
+	}
+}
+
+func TestListKnowledge_NoValidatorsForPaginatedList(t *testing.T) {
+	server := newPaginatedServer(t, 3, "cursor")
+	client, err := NewClient("key", server.URL)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	client.PageSize = 2
+
+	_, validators, notModified, err := client.fetchAllKnowledgePages(context.Background(), knowledgeValidators{ETag: `"v1"`})
+	if err != nil {
+		t.Fatalf("fetchAllKnowledgePages() error = %v", err)
+	}
+	if notModified || !validators.empty() {
+		t.Errorf("fetchAllKnowledgePages() validators = %+v, notModified = %v, want none", validators, notModified)
+	}
+}
+
+func TestKnowledgeMutations_FailuresInvalidateCacheOnlyIfMayBeApplied(t *testing.T) {
+	mutations := map[string]func(context.Context, *DevinClient) error{
+		"create": func(ctx context.Context, c *DevinClient) error {
+			_, err := c.CreateKnowledge(ctx, "Knowledge", "body", "trigger", "")
+			return err
+		},
+		"update": func(ctx context.Context, c *DevinClient) error {
+			_, err := c.UpdateKnowledge(ctx, "k1", "Renamed", "body", "trigger", "")
+			return err
+		},
+		"delete": func(ctx context.Context, c *DevinClient) error {
+			return c.DeleteKnowledge(ctx, "k1")
+		},
+	}
+	failures := []struct {
+		name string
+		// respond answers the mutation request
+		respond func() (*http.Response, error)
+		// setup runs after the cache is filled and may stop the request
+		// before it is sent
+		setup      func(ctx context.Context, c *DevinClient) context.Context
+		invalidate bool
+	}{
+		{
+			name: "network error",
+			// The server may have applied the request before the connection dropped
+			respond:    func() (*http.Response, error) { return nil, errors.New("connection reset by peer") },
+			invalidate: true,
+		},
+		{
+			name:       "server error",
+			respond:    func() (*http.Response, error) { return stubResponse(http.StatusInternalServerError, ""), nil },
+			invalidate: true,
+		},
+		{
+			name:    "rejected",
+			respond: func() (*http.Response, error) { return stubResponse(http.StatusBadRequest, ""), nil },
+		},
+		{
+			name: "circuit open",
+			setup: func(ctx context.Context, c *DevinClient) context.Context {
+				c.SetCircuitBreaker(1, time.Minute)
+				c.breaker.open = true
+				c.breaker.openedAt = time.Now()
+				return ctx
+			},
+		},
+		{
+			name: "rate limit wait aborted",
+			setup: func(ctx context.Context, c *DevinClient) context.Context {
+				c.SetRateLimit(0.001, 1)
+				c.limiter.Allow()
+				ctx, cancel := context.WithCancel(ctx)
+				cancel()
+				return ctx
+			},
+		},
+	}
+
+	for mutation, mutate := range mutations {
+		for _, failure := range failures {
+			t.Run(mutation+"/"+failure.name, func(t *testing.T) {
+				var listCalls, mutationCalls int32
+				client := newStubClient(t, func(req *http.Request) (*http.Response, error) {
+					if req.Method == http.MethodGet {
+						atomic.AddInt32(&listCalls, 1)
+						return stubResponse(http.StatusOK, `{"knowledge":[{"id":"k1","name":"Knowledge 1"}],"folders":[]}`), nil
+					}
+					atomic.AddInt32(&mutationCalls, 1)
+					return failure.respond()
+				})
+				client.MaxRetries = 0
+				ctx := context.Background()
+
+				if _, err := client.ListKnowledge(ctx); err != nil {
+					t.Fatalf("ListKnowledge() error = %v", err)
+				}
+				mutateCtx := ctx
+				if failure.setup != nil {
+					mutateCtx = failure.setup(ctx, client)
+				}
+				if err := mutate(mutateCtx, client); err == nil {
+					t.Fatalf("%s should return an error", mutation)
+				}
+				if failure.respond == nil && mutationCalls != 0 {
+					t.Fatalf("%s sent %d requests, want none", mutation, mutationCalls)
+				}
+
+				client.SetCircuitBreaker(0, 0)
+				client.limiter = nil
+				if _, err := client.ListKnowledge(ctx); err != nil {
+					t.Fatalf("ListKnowledge() error = %v", err)
+				}
+				want := int32(1)
+				if failure.invalidate {
+					want = 2
+				}
+				if n := atomic.LoadInt32(&listCalls); n != want {
+					t.Errorf("list endpoint called %d times, want %d", n, want)
+				}
+			})
+		}
+	}
+}
@@ -2,17 +2,19 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 // KnowledgeDataSource defines the type for knowledge data source
 type KnowledgeDataSource struct {
-	client *DevinClient
+	client DevinAPI
 }
 
 // KnowledgeDataSourceModel represents the schema structure for the Terraform data source
@@ -69,11 +71,11 @@ func (d *KnowledgeDataSource) Configure(_ context.Context, req datasource.Config
 		return
 	}
 
-	client, ok := req.ProviderData.(*DevinClient)
+	client, ok := req.ProviderData.(DevinAPI)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected data source configure type",
-			fmt.Sprintf("Expected *DevinClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected DevinAPI, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 		return
 	}
@@ -83,6 +85,13 @@ func (d *KnowledgeDataSource) Configure(_ context.Context, req datasource.Config
 
 // Read reads the knowledge information
 func (d *KnowledgeDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	ctx, span := startSpan(ctx, "data.devin_knowledge.Read")
+	ctx, schemaWarnings := withSchemaWarnings(ctx)
+	defer func() {
+		addSchemaWarnings(schemaWarnings, &resp.Diagnostics)
+		endHandlerSpan(span, resp.Diagnostics)
+	}()
+
 	var config KnowledgeDataSourceModel
 	diags := req.Config.Get(ctx, &config)
 	resp.Diagnostics.Append(diags...)
@@ -96,11 +105,19 @@ func (d *KnowledgeDataSource) Read(ctx context.Context, req datasource.ReadReque
 	})
 
 	// Get knowledge
-	knowledge, err := d.client.GetKnowledge(knowledgeID)
+	knowledge, err := d.client.GetKnowledge(ctx, knowledgeID)
 	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("id"),
+				"Knowledge not found",
+				fmt.Sprintf("No knowledge resource with ID '%s' exists in the Devin API.", knowledgeID),
+			)
+			return
+		}
 		resp.Diagnostics.AddError(
 			"Failed to retrieve knowledge",
-			fmt.Sprintf("Error during Devin API request: %s", err),
+			apiErrorDetail(err),
 		)
 		return
 	}
@@ -2,8 +2,11 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
@@ -13,20 +16,37 @@ import (
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
+// Default time limit for each knowledge operation, including retries
+const defaultKnowledgeTimeout = 20 * time.Minute
+
 // KnowledgeResource defines the type for knowledge resources
 type KnowledgeResource struct {
-	client *DevinClient
+	client DevinAPI
 }
 
 // KnowledgeResourceModel represents the schema structure for the Terraform resource
 type KnowledgeResourceModel struct {
-	ID                 types.String `tfsdk:"id"`
-	Name               types.String `tfsdk:"name"`
-	Body               types.String `tfsdk:"body"`
-	TriggerDescription types.String `tfsdk:"trigger_description"`
-	ParentFolderID     types.String `tfsdk:"parent_folder_id"`
+	ID                 types.String   `tfsdk:"id"`
+	Name               types.String   `tfsdk:"name"`
+	Body               types.String   `tfsdk:"body"`
+	TriggerDescription types.String   `tfsdk:"trigger_description"`
+	ParentFolderID     types.String   `tfsdk:"parent_folder_id"`
+	AdoptExisting      types.Bool     `tfsdk:"adopt_existing"`
+	Timeouts           timeouts.Value `tfsdk:"timeouts"`
+}
+
+// sameKnowledge reports whether m and other describe the same knowledge in
+// the Devin API, ignoring attributes that only affect Terraform
+func (m KnowledgeResourceModel) sameKnowledge(other KnowledgeResourceModel) bool {
+	return m.Name.ValueString() == other.Name.ValueString() &&
+		m.Body.ValueString() == other.Body.ValueString() &&
+		m.TriggerDescription.ValueString() == other.TriggerDescription.ValueString() &&
+		m.ParentFolderID.ValueString() == other.ParentFolderID.ValueString()
 }
 
+// Ensure KnowledgeResource warns about adoption during plan
+var _ resource.ResourceWithModifyPlan = &KnowledgeResource{}
+
 // NewKnowledgeResource creates an instance of the knowledge resource
 func NewKnowledgeResource() resource.Resource {
 	return &KnowledgeResource{}
@@ -38,7 +58,7 @@ func (r *KnowledgeResource) Metadata(_ context.Context, req resource.MetadataReq
 }
 
 // Schema defines the resource schema
-func (r *KnowledgeResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+func (r *KnowledgeResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Description: "Manages knowledge resources in the Devin API",
 		Attributes: map[string]schema.Attribute{
@@ -65,6 +85,18 @@ func (r *KnowledgeResource) Schema(_ context.Context, _ resource.SchemaRequest,
 				Description: "The ID of the parent folder",
 				Optional:    true,
 			},
+			"adopt_existing": schema.BoolAttribute{
+				Description: "On create, take over an existing knowledge resource with the same name and parent folder, updating it in place instead of creating a duplicate. Creation fails if several resources match. Changing it after creation only updates the Terraform state. Defaults to false.",
+				Optional:    true,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
 		},
 	}
 }
@@ -75,11 +107,11 @@ func (r *KnowledgeResource) Configure(_ context.Context, req resource.ConfigureR
 		return
 	}
 
-	client, ok := req.ProviderData.(*DevinClient)
+	client, ok := req.ProviderData.(DevinAPI)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected resource configure type",
-			fmt.Sprintf("Expected *DevinClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected DevinAPI, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 		return
 	}
@@ -89,6 +121,13 @@ func (r *KnowledgeResource) Configure(_ context.Context, req resource.ConfigureR
 
 // Create creates a knowledge resource
 func (r *KnowledgeResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx, span := startSpan(ctx, "devin_knowledge.Create")
+	ctx, schemaWarnings := withSchemaWarnings(ctx)
+	defer func() {
+		addSchemaWarnings(schemaWarnings, &resp.Diagnostics)
+		endHandlerSpan(span, resp.Diagnostics)
+	}()
+
 	var plan KnowledgeResourceModel
 	diags := req.Plan.Get(ctx, &plan)
 	resp.Diagnostics.Append(diags...)
@@ -98,23 +137,51 @@ func (r *KnowledgeResource) Create(ctx context.Context, req resource.CreateReque
 
 	tflog.Info(ctx, "Starting knowledge resource creation")
 
+	createTimeout, diags := plan.Timeouts.Create(ctx, defaultKnowledgeTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	// Adopt a matching knowledge resource, such as one left behind by a
+	// create whose response was lost, instead of creating a duplicate
+	var knowledge *Knowledge
+	var err error
+	if plan.AdoptExisting.ValueBool() {
+		knowledge, err = r.adoptKnowledge(ctx, plan)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Failed to adopt existing knowledge",
+				apiErrorDetail(err),
+			)
+			return
+		}
+	}
+
 	// Create knowledge
-	knowledge, err := r.client.CreateKnowledge(
-		plan.Name.ValueString(),
-		plan.Body.ValueString(),
-		plan.TriggerDescription.ValueString(),
-		plan.ParentFolderID.ValueString(),
-	)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Failed to create knowledge",
-			fmt.Sprintf("Error during Devin API request: %s", err),
+	if knowledge == nil {
+		knowledge, err = r.client.CreateKnowledge(
+			ctx,
+			plan.Name.ValueString(),
+			plan.Body.ValueString(),
+			plan.TriggerDescription.ValueString(),
+			plan.ParentFolderID.ValueString(),
 		)
-		return
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Failed to create knowledge",
+				apiErrorDetail(err),
+			)
+			return
+		}
 	}
 
 	// Update model
 	plan.ID = types.StringValue(knowledge.ID)
+	span.SetAttributes(attrResourceID.String(knowledge.ID))
 
 	// Save state
 	diags = resp.State.Set(ctx, plan)
@@ -128,8 +195,84 @@ func (r *KnowledgeResource) Create(ctx context.Context, req resource.CreateReque
 	})
 }
 
+// adoptKnowledge updates the existing knowledge resource matching the plan's
+// name and parent folder, returning nil if there is none
+func (r *KnowledgeResource) adoptKnowledge(ctx context.Context, plan KnowledgeResourceModel) (*Knowledge, error) {
+	existing, err := r.client.FindKnowledge(ctx, plan.Name.ValueString(), plan.ParentFolderID.ValueString())
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tflog.Warn(ctx, "Adopting existing knowledge resource", map[string]interface{}{
+		"id":   existing.ID,
+		"name": existing.Name,
+	})
+
+	knowledge, err := r.client.UpdateKnowledge(
+		ctx,
+		existing.ID,
+		plan.Name.ValueString(),
+		plan.Body.ValueString(),
+		plan.TriggerDescription.ValueString(),
+		plan.ParentFolderID.ValueString(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	// The ID is kept even if the response omits it
+	knowledge.ID = existing.ID
+	return knowledge, nil
+}
+
+// ModifyPlan warns when a create will adopt an existing knowledge resource
+func (r *KnowledgeResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Only creates adopt, and only once the provider is configured
+	if !req.State.Raw.IsNull() || req.Plan.Raw.IsNull() || r.client == nil {
+		return
+	}
+
+	var plan KnowledgeResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if !plan.AdoptExisting.ValueBool() || plan.Name.IsUnknown() || plan.ParentFolderID.IsUnknown() {
+		return
+	}
+
+	ctx, schemaWarnings := withSchemaWarnings(ctx)
+	defer addSchemaWarnings(schemaWarnings, &resp.Diagnostics)
+
+	existing, err := r.client.FindKnowledge(ctx, plan.Name.ValueString(), plan.ParentFolderID.ValueString())
+	switch {
+	case errors.Is(err, ErrNotFound):
+	case err != nil:
+		resp.Diagnostics.AddAttributeWarning(
+			path.Root("adopt_existing"),
+			"Cannot check for existing knowledge",
+			fmt.Sprintf("The knowledge to adopt is looked up again during apply.\n\n%s", apiErrorDetail(err)),
+		)
+	default:
+		resp.Diagnostics.AddAttributeWarning(
+			path.Root("adopt_existing"),
+			"Existing knowledge will be adopted",
+			fmt.Sprintf("Knowledge '%s' (ID %s) already exists in this folder. Apply will update it in place and manage it with this resource instead of creating a new one.", existing.Name, existing.ID),
+		)
+	}
+}
+
 // Read reads a knowledge resource
 func (r *KnowledgeResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx, span := startSpan(ctx, "devin_knowledge.Read")
+	ctx, schemaWarnings := withSchemaWarnings(ctx)
+	defer func() {
+		addSchemaWarnings(schemaWarnings, &resp.Diagnostics)
+		endHandlerSpan(span, resp.Diagnostics)
+	}()
+
 	var state KnowledgeResourceModel
 	diags := req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
@@ -140,13 +283,32 @@ func (r *KnowledgeResource) Read(ctx context.Context, req resource.ReadRequest,
 	tflog.Info(ctx, "Retrieving knowledge resource information", map[string]interface{}{
 		"id": state.ID.ValueString(),
 	})
+	span.SetAttributes(attrResourceID.String(state.ID.ValueString()))
+
+	readTimeout, diags := state.Timeouts.Read(ctx, defaultKnowledgeTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
 
 	// Get knowledge
-	knowledge, err := r.client.GetKnowledge(state.ID.ValueString())
+	knowledge, err := r.client.GetKnowledge(ctx, state.ID.ValueString())
 	if err != nil {
+		// The knowledge was deleted outside of Terraform; drop it from state
+		// so that the next plan recreates it instead of failing
+		if errors.Is(err, ErrNotFound) {
+			tflog.Warn(ctx, "Knowledge resource not found, removing from state", map[string]interface{}{
+				"id": state.ID.ValueString(),
+			})
+			resp.State.RemoveResource(ctx)
+			return
+		}
 		resp.Diagnostics.AddError(
 			"Failed to retrieve knowledge",
-			fmt.Sprintf("Error during Devin API request: %s", err),
+			apiErrorDetail(err),
 		)
 		return
 	}
@@ -176,6 +338,13 @@ func (r *KnowledgeResource) Read(ctx context.Context, req resource.ReadRequest,
 
 // Update updates a knowledge resource
 func (r *KnowledgeResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx, span := startSpan(ctx, "devin_knowledge.Update")
+	ctx, schemaWarnings := withSchemaWarnings(ctx)
+	defer func() {
+		addSchemaWarnings(schemaWarnings, &resp.Diagnostics)
+		endHandlerSpan(span, resp.Diagnostics)
+	}()
+
 	var plan KnowledgeResourceModel
 	diags := req.Plan.Get(ctx, &plan)
 	resp.Diagnostics.Append(diags...)
@@ -193,12 +362,33 @@ func (r *KnowledgeResource) Update(ctx context.Context, req resource.UpdateReque
 	tflog.Info(ctx, "Starting knowledge resource update", map[string]interface{}{
 		"id": state.ID.ValueString(),
 	})
+	span.SetAttributes(attrResourceID.String(state.ID.ValueString()))
+
+	updateTimeout, diags := plan.Timeouts.Update(ctx, defaultKnowledgeTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
 
 	// Maintain existing ID
 	plan.ID = state.ID
 
+	// Only Terraform-side settings such as adopt_existing or timeouts
+	// changed, so there is nothing to send to the API
+	if plan.sameKnowledge(state) {
+		tflog.Debug(ctx, "Knowledge unchanged, skipping API update", map[string]interface{}{
+			"id": state.ID.ValueString(),
+		})
+		resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+		return
+	}
+
 	// Update knowledge
 	_, err := r.client.UpdateKnowledge(
+		ctx,
 		state.ID.ValueString(),
 		plan.Name.ValueString(),
 		plan.Body.ValueString(),
@@ -208,7 +398,7 @@ func (r *KnowledgeResource) Update(ctx context.Context, req resource.UpdateReque
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to update knowledge",
-			fmt.Sprintf("Error during Devin API request: %s", err),
+			apiErrorDetail(err),
 		)
 		return
 	}
@@ -225,6 +415,13 @@ func (r *KnowledgeResource) Update(ctx context.Context, req resource.UpdateReque
 
 // Delete deletes a knowledge resource
 func (r *KnowledgeResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx, span := startSpan(ctx, "devin_knowledge.Delete")
+	ctx, schemaWarnings := withSchemaWarnings(ctx)
+	defer func() {
+		addSchemaWarnings(schemaWarnings, &resp.Diagnostics)
+		endHandlerSpan(span, resp.Diagnostics)
+	}()
+
 	var state KnowledgeResourceModel
 	diags := req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
@@ -235,13 +432,30 @@ func (r *KnowledgeResource) Delete(ctx context.Context, req resource.DeleteReque
 	tflog.Info(ctx, "Starting knowledge resource deletion", map[string]interface{}{
 		"id": state.ID.ValueString(),
 	})
+	span.SetAttributes(attrResourceID.String(state.ID.ValueString()))
+
+	deleteTimeout, diags := state.Timeouts.Delete(ctx, defaultKnowledgeTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
 
 	// Delete knowledge
-	err := r.client.DeleteKnowledge(state.ID.ValueString())
+	err := r.client.DeleteKnowledge(ctx, state.ID.ValueString())
+	if errors.Is(err, ErrNotFound) {
+		// Already gone, which is the desired outcome
+		tflog.Warn(ctx, "Knowledge resource already deleted", map[string]interface{}{
+			"id": state.ID.ValueString(),
+		})
+		err = nil
+	}
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to delete knowledge",
-			fmt.Sprintf("Error during Devin API request: %s", err),
+			apiErrorDetail(err),
 		)
 		return
 	}
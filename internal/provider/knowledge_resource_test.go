@@ -0,0 +1,439 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// newKnowledgeState builds a resource state holding the given model
+func newKnowledgeState(t *testing.T, model KnowledgeResourceModel) tfsdk.State {
+	t.Helper()
+	ctx := context.Background()
+
+	var schemaResp resource.SchemaResponse
+	(&KnowledgeResource{}).Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+
+	state := tfsdk.State{
+		Schema: schemaResp.Schema,
+		Raw:    tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil),
+	}
+	if diags := state.Set(ctx, model); diags.HasError() {
+		t.Fatalf("State.Set() error = %v", diags)
+	}
+	return state
+}
+
+// testKnowledgeModel returns a populated knowledge model
+func testKnowledgeModel(id string) KnowledgeResourceModel {
+	return KnowledgeResourceModel{
+		ID:                 types.StringValue(id),
+		Name:               types.StringValue("Knowledge"),
+		Body:               types.StringValue("body"),
+		TriggerDescription: types.StringValue("trigger"),
+		ParentFolderID:     types.StringNull(),
+		Timeouts:           timeoutsValue(nil),
+	}
+}
+
+// timeoutsValue builds a timeouts block with the given durations, or a null
+// block when values is nil
+func timeoutsValue(values map[string]string) timeouts.Value {
+	attrTypes := map[string]attr.Type{
+		"create": types.StringType,
+		"read":   types.StringType,
+		"update": types.StringType,
+		"delete": types.StringType,
+	}
+	if values == nil {
+		return timeouts.Value{Object: types.ObjectNull(attrTypes)}
+	}
+	attrs := make(map[string]attr.Value, len(attrTypes))
+	for name := range attrTypes {
+		if v, ok := values[name]; ok {
+			attrs[name] = types.StringValue(v)
+		} else {
+			attrs[name] = types.StringNull()
+		}
+	}
+	return timeouts.Value{Object: types.ObjectValueMust(attrTypes, attrs)}
+}
+
+func TestKnowledgeResourceRead_RemovesMissingResource(t *testing.T) {
+	client := newStubClient(t, func(req *http.Request) (*http.Response, error) {
+		return stubResponse(http.StatusOK, `{"knowledge":[],"folders":[]}`), nil
+	})
+	r := &KnowledgeResource{client: client}
+
+	state := newKnowledgeState(t, testKnowledgeModel("deleted-in-ui"))
+	resp := resource.ReadResponse{State: state}
+	r.Read(context.Background(), resource.ReadRequest{State: state}, &resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Read() unexpected error: %v", resp.Diagnostics)
+	}
+	if !resp.State.Raw.IsNull() {
+		t.Error("Read() should remove a missing resource from state")
+	}
+}
+
+func TestKnowledgeResourceRead_KeepsStateOnAPIError(t *testing.T) {
+	client := newStubClient(t, func(req *http.Request) (*http.Response, error) {
+		return stubResponse(http.StatusUnauthorized, `{"error":{"message":"invalid key","type":"auth_error"}}`), nil
+	})
+	r := &KnowledgeResource{client: client}
+
+	state := newKnowledgeState(t, testKnowledgeModel("k1"))
+	resp := resource.ReadResponse{State: state}
+	r.Read(context.Background(), resource.ReadRequest{State: state}, &resp)
+
+	if !resp.Diagnostics.HasError() {
+		t.Fatal("Read() should report an API failure as an error")
+	}
+	if resp.State.Raw.IsNull() {
+		t.Error("Read() should not remove the resource on API failure")
+	}
+}
+
+func TestKnowledgeResourceDelete_AlreadyDeleted(t *testing.T) {
+	client := newStubClient(t, func(req *http.Request) (*http.Response, error) {
+		return stubResponse(http.StatusNotFound, `{"error":{"message":"not found","type":"not_found"}}`), nil
+	})
+	r := &KnowledgeResource{client: client}
+
+	state := newKnowledgeState(t, testKnowledgeModel("k1"))
+	resp := resource.DeleteResponse{State: state}
+	r.Delete(context.Background(), resource.DeleteRequest{State: state}, &resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Delete() unexpected error: %v", resp.Diagnostics)
+	}
+}
+
+// newKnowledgePlan builds a resource plan holding the given model
+func newKnowledgePlan(t *testing.T, model KnowledgeResourceModel) tfsdk.Plan {
+	t.Helper()
+	state := newKnowledgeState(t, model)
+	return tfsdk.Plan{Schema: state.Schema, Raw: state.Raw}
+}
+
+// readKnowledgeState decodes a resource state into a model
+func readKnowledgeState(t *testing.T, state tfsdk.State) KnowledgeResourceModel {
+	t.Helper()
+	var model KnowledgeResourceModel
+	if diags := state.Get(context.Background(), &model); diags.HasError() {
+		t.Fatalf("State.Get() error = %v", diags)
+	}
+	return model
+}
+
+func TestKnowledgeResource_Lifecycle(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeBackend()
+	folder := fake.AddFolder("Folder", "")
+	r := &KnowledgeResource{client: fake}
+
+	// Create
+	model := testKnowledgeModel("")
+	model.ID = types.StringUnknown()
+	model.ParentFolderID = types.StringValue(folder.ID)
+	createResp := resource.CreateResponse{State: newKnowledgeState(t, testKnowledgeModel(""))}
+	r.Create(ctx, resource.CreateRequest{Plan: newKnowledgePlan(t, model)}, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("Create() unexpected error: %v", createResp.Diagnostics)
+	}
+	created := readKnowledgeState(t, createResp.State)
+	if created.ID.ValueString() == "" {
+		t.Fatal("Create() did not set an ID")
+	}
+
+	// Read
+	readResp := resource.ReadResponse{State: createResp.State}
+	r.Read(ctx, resource.ReadRequest{State: createResp.State}, &readResp)
+	if readResp.Diagnostics.HasError() {
+		t.Fatalf("Read() unexpected error: %v", readResp.Diagnostics)
+	}
+	if got := readKnowledgeState(t, readResp.State); got.ParentFolderID.ValueString() != folder.ID {
+		t.Errorf("Read() ParentFolderID = %s, want %s", got.ParentFolderID.ValueString(), folder.ID)
+	}
+
+	// Update
+	updated := created
+	updated.Body = types.StringValue("updated body")
+	updateResp := resource.UpdateResponse{State: readResp.State}
+	r.Update(ctx, resource.UpdateRequest{Plan: newKnowledgePlan(t, updated), State: readResp.State}, &updateResp)
+	if updateResp.Diagnostics.HasError() {
+		t.Fatalf("Update() unexpected error: %v", updateResp.Diagnostics)
+	}
+	knowledge, err := fake.GetKnowledge(ctx, created.ID.ValueString())
+	if err != nil {
+		t.Fatalf("GetKnowledge() error = %v", err)
+	}
+	if knowledge.Body != "updated body" {
+		t.Errorf("Update() body = %s, want updated body", knowledge.Body)
+	}
+
+	// Delete
+	deleteResp := resource.DeleteResponse{State: updateResp.State}
+	r.Delete(ctx, resource.DeleteRequest{State: updateResp.State}, &deleteResp)
+	if deleteResp.Diagnostics.HasError() {
+		t.Fatalf("Delete() unexpected error: %v", deleteResp.Diagnostics)
+	}
+
+	// A refresh after deletion removes the resource from state
+	readResp = resource.ReadResponse{State: updateResp.State}
+	r.Read(ctx, resource.ReadRequest{State: updateResp.State}, &readResp)
+	if readResp.Diagnostics.HasError() {
+		t.Fatalf("Read() unexpected error: %v", readResp.Diagnostics)
+	}
+	if !readResp.State.Raw.IsNull() {
+		t.Error("Read() should remove deleted knowledge from state")
+	}
+}
+
+func TestKnowledgeResource_Import(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeBackend()
+	existing, err := fake.CreateKnowledge(ctx, "Existing", "body", "trigger", "")
+	if err != nil {
+		t.Fatalf("CreateKnowledge() error = %v", err)
+	}
+	r := &KnowledgeResource{client: fake}
+
+	empty := newKnowledgeState(t, testKnowledgeModel(""))
+	empty.Raw = tftypes.NewValue(empty.Schema.Type().TerraformType(ctx), nil)
+	importResp := resource.ImportStateResponse{State: empty}
+	r.ImportState(ctx, resource.ImportStateRequest{ID: existing.ID}, &importResp)
+	if importResp.Diagnostics.HasError() {
+		t.Fatalf("ImportState() unexpected error: %v", importResp.Diagnostics)
+	}
+
+	readResp := resource.ReadResponse{State: importResp.State}
+	r.Read(ctx, resource.ReadRequest{State: importResp.State}, &readResp)
+	if readResp.Diagnostics.HasError() {
+		t.Fatalf("Read() unexpected error: %v", readResp.Diagnostics)
+	}
+	got := readKnowledgeState(t, readResp.State)
+	if got.ID.ValueString() != existing.ID || got.Name.ValueString() != "Existing" || got.Body.ValueString() != "body" {
+		t.Errorf("imported state = %+v, want %+v", got, existing)
+	}
+}
+
+// deadlineAPI records the deadline of the context passed to CreateKnowledge
+type deadlineAPI struct {
+	*fakeBackend
+	deadline time.Time
+}
+
+func (a *deadlineAPI) CreateKnowledge(ctx context.Context, name, body, triggerDescription, parentFolderID string) (*Knowledge, error) {
+	a.deadline, _ = ctx.Deadline()
+	return a.fakeBackend.CreateKnowledge(ctx, name, body, triggerDescription, parentFolderID)
+}
+
+func TestKnowledgeResourceCreate_Timeout(t *testing.T) {
+	api := &deadlineAPI{fakeBackend: newFakeBackend()}
+	r := &KnowledgeResource{client: api}
+
+	model := testKnowledgeModel("")
+	model.ID = types.StringUnknown()
+	model.Timeouts = timeoutsValue(map[string]string{"create": "2m"})
+	plan := newKnowledgePlan(t, model)
+	resp := resource.CreateResponse{State: tfsdk.State{Schema: plan.Schema, Raw: plan.Raw}}
+	start := time.Now()
+	r.Create(context.Background(), resource.CreateRequest{Plan: plan}, &resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Create() unexpected error: %v", resp.Diagnostics)
+	}
+	if d := api.deadline.Sub(start); d < 2*time.Minute || d > 2*time.Minute+time.Second {
+		t.Errorf("CreateKnowledge() deadline = %v after start, want 2m", d)
+	}
+	if got := readKnowledgeState(t, resp.State).Timeouts; !got.Equal(model.Timeouts) {
+		t.Errorf("Create() timeouts = %v, want %v", got, model.Timeouts)
+	}
+}
+
+func TestKnowledgeResourceUpdate_TimeoutStopsRetries(t *testing.T) {
+	var calls int32
+	client := newStubClient(t, func(req *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&calls, 1)
+		resp := stubResponse(http.StatusTooManyRequests, `{"error":{"message":"slow down","type":"rate_limit"}}`)
+		resp.Header.Set("Retry-After", "60")
+		return resp, nil
+	})
+	client.RetryMaxWait = time.Minute
+	r := &KnowledgeResource{client: client}
+
+	model := testKnowledgeModel("k1")
+	model.Body = types.StringValue("new body")
+	model.Timeouts = timeoutsValue(map[string]string{"update": "5s"})
+	state := newKnowledgeState(t, testKnowledgeModel("k1"))
+	plan := newKnowledgePlan(t, model)
+	resp := resource.UpdateResponse{State: state}
+	start := time.Now()
+	r.Update(context.Background(), resource.UpdateRequest{Plan: plan, State: state}, &resp)
+
+	if !resp.Diagnostics.HasError() {
+		t.Fatal("Update() should fail once the timeout cannot cover the next retry")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Update() took %v, want it to give up without waiting", elapsed)
+	}
+	if calls != 1 {
+		t.Errorf("Update() made %d requests, want 1", calls)
+	}
+	if detail := resp.Diagnostics[0].Detail(); !strings.Contains(detail, "timeouts block") {
+		t.Errorf("Update() detail = %q, want a hint about the timeouts block", detail)
+	}
+}
+
+func TestKnowledgeResourceUpdate_TerraformOnlyChangeSkipsAPI(t *testing.T) {
+	var calls int32
+	client := newStubClient(t, func(req *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&calls, 1)
+		return stubResponse(http.StatusOK, `{"id":"k1"}`), nil
+	})
+	r := &KnowledgeResource{client: client}
+
+	model := testKnowledgeModel("k1")
+	model.AdoptExisting = types.BoolValue(true)
+	state := newKnowledgeState(t, testKnowledgeModel("k1"))
+	resp := resource.UpdateResponse{State: state}
+	r.Update(context.Background(), resource.UpdateRequest{Plan: newKnowledgePlan(t, model), State: state}, &resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Update() unexpected error: %v", resp.Diagnostics)
+	}
+	if calls != 0 {
+		t.Errorf("Update() made %d requests, want none when only adopt_existing changed", calls)
+	}
+	if got := readKnowledgeState(t, resp.State); !got.AdoptExisting.ValueBool() {
+		t.Errorf("Update() state adopt_existing = %v, want true", got.AdoptExisting)
+	}
+}
+
+func TestKnowledgeResourceCreate_InvalidTimeout(t *testing.T) {
+	fake := newFakeBackend()
+	r := &KnowledgeResource{client: fake}
+
+	model := testKnowledgeModel("")
+	model.ID = types.StringUnknown()
+	model.Timeouts = timeoutsValue(map[string]string{"create": "soon"})
+	plan := newKnowledgePlan(t, model)
+	resp := resource.CreateResponse{State: tfsdk.State{Schema: plan.Schema, Raw: plan.Raw}}
+	r.Create(context.Background(), resource.CreateRequest{Plan: plan}, &resp)
+
+	if !resp.Diagnostics.HasError() {
+		t.Fatal("Create() should reject an unparsable timeout")
+	}
+	if list, _ := fake.ListKnowledge(context.Background()); len(list.Knowledge) != 0 {
+		t.Errorf("Create() created %d knowledge items, want 0", len(list.Knowledge))
+	}
+}
+
+// newAdoptPlan builds a create plan with adopt_existing set
+func newAdoptPlan(t *testing.T, parentFolderID string) tfsdk.Plan {
+	t.Helper()
+	model := testKnowledgeModel("")
+	model.ID = types.StringUnknown()
+	model.Body = types.StringValue("new body")
+	model.AdoptExisting = types.BoolValue(true)
+	if parentFolderID != "" {
+		model.ParentFolderID = types.StringValue(parentFolderID)
+	}
+	return newKnowledgePlan(t, model)
+}
+
+func TestKnowledgeResourceCreate_AdoptExisting(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeBackend()
+	folder := fake.AddFolder("Folder", "")
+	existing, _ := fake.CreateKnowledge(ctx, "Knowledge", "old body", "trigger", folder.ID)
+	// Same name in another folder is not adopted
+	if _, err := fake.CreateKnowledge(ctx, "Knowledge", "other body", "trigger", ""); err != nil {
+		t.Fatalf("CreateKnowledge() error = %v", err)
+	}
+	r := &KnowledgeResource{client: fake}
+	plan := newAdoptPlan(t, folder.ID)
+
+	// The plan warns about the adoption
+	emptyState := tfsdk.State{Schema: plan.Schema, Raw: tftypes.NewValue(plan.Raw.Type(), nil)}
+	planResp := resource.ModifyPlanResponse{Plan: plan}
+	r.ModifyPlan(ctx, resource.ModifyPlanRequest{Plan: plan, State: emptyState}, &planResp)
+	if planResp.Diagnostics.HasError() || planResp.Diagnostics.WarningsCount() != 1 {
+		t.Fatalf("ModifyPlan() diagnostics = %v, want one warning", planResp.Diagnostics)
+	}
+	if summary := planResp.Diagnostics[0].Summary(); summary != "Existing knowledge will be adopted" {
+		t.Errorf("ModifyPlan() warning = %q", summary)
+	}
+
+	createResp := resource.CreateResponse{State: emptyState}
+	r.Create(ctx, resource.CreateRequest{Plan: plan}, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("Create() unexpected error: %v", createResp.Diagnostics)
+	}
+	if got := readKnowledgeState(t, createResp.State).ID.ValueString(); got != existing.ID {
+		t.Errorf("Create() ID = %s, want adopted %s", got, existing.ID)
+	}
+	adopted, _ := fake.GetKnowledge(ctx, existing.ID)
+	if adopted.Body != "new body" {
+		t.Errorf("adopted knowledge body = %s, want new body", adopted.Body)
+	}
+	if list, _ := fake.ListKnowledge(ctx); len(list.Knowledge) != 2 {
+		t.Errorf("Create() left %d knowledge items, want 2", len(list.Knowledge))
+	}
+}
+
+func TestKnowledgeResourceCreate_AdoptExistingNoMatch(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeBackend()
+	r := &KnowledgeResource{client: fake}
+	plan := newAdoptPlan(t, "")
+
+	emptyState := tfsdk.State{Schema: plan.Schema, Raw: tftypes.NewValue(plan.Raw.Type(), nil)}
+	planResp := resource.ModifyPlanResponse{Plan: plan}
+	r.ModifyPlan(ctx, resource.ModifyPlanRequest{Plan: plan, State: emptyState}, &planResp)
+	if len(planResp.Diagnostics) != 0 {
+		t.Errorf("ModifyPlan() diagnostics = %v, want none", planResp.Diagnostics)
+	}
+
+	createResp := resource.CreateResponse{State: emptyState}
+	r.Create(ctx, resource.CreateRequest{Plan: plan}, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("Create() unexpected error: %v", createResp.Diagnostics)
+	}
+	if list, _ := fake.ListKnowledge(ctx); len(list.Knowledge) != 1 {
+		t.Errorf("Create() left %d knowledge items, want 1", len(list.Knowledge))
+	}
+}
+
+func TestKnowledgeResourceCreate_AdoptExistingAmbiguous(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeBackend()
+	for i := 0; i < 2; i++ {
+		if _, err := fake.CreateKnowledge(ctx, "Knowledge", "body", "trigger", ""); err != nil {
+			t.Fatalf("CreateKnowledge() error = %v", err)
+		}
+	}
+	r := &KnowledgeResource{client: fake}
+	plan := newAdoptPlan(t, "")
+
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: plan.Schema, Raw: tftypes.NewValue(plan.Raw.Type(), nil)}}
+	r.Create(ctx, resource.CreateRequest{Plan: plan}, &createResp)
+	if !createResp.Diagnostics.HasError() {
+		t.Fatal("Create() should fail when several knowledge resources match")
+	}
+	if list, _ := fake.ListKnowledge(ctx); len(list.Knowledge) != 2 {
+		t.Errorf("Create() left %d knowledge items, want 2", len(list.Knowledge))
+	}
+}
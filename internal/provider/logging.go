@@ -0,0 +1,188 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	// tflog subsystem for HTTP traffic, enabled with TF_LOG or
+	// TF_LOG_PROVIDER_DEVIN_API
+	logSubsystem = "devin_api"
+	// Maximum number of body bytes written to the log
+	maxLoggedBodySize = 4096
+	// Replacement for redacted values
+	redactedValue = "***"
+)
+
+// secretFieldMarkers identify JSON fields whose values must never be logged.
+// A field is secret if its lower-cased name contains any of them.
+var secretFieldMarkers = []string{"api_key", "apikey", "authorization", "password", "secret", "token", "credential"}
+
+// withHTTPLogging returns a context whose devin_api subsystem logger masks
+// the Authorization header and the API key wherever they appear
+func (c *DevinClient) withHTTPLogging(ctx context.Context) context.Context {
+	ctx = tflog.NewSubsystem(ctx, logSubsystem, tflog.WithLevelFromEnv("TF_LOG_PROVIDER_DEVIN_API"))
+	ctx = tflog.SubsystemMaskFieldValuesWithFieldKeys(ctx, logSubsystem, "authorization")
+	if c.APIKey != "" {
+		ctx = tflog.SubsystemMaskAllFieldValuesStrings(ctx, logSubsystem, c.APIKey)
+		ctx = tflog.SubsystemMaskMessageStrings(ctx, logSubsystem, c.APIKey)
+	}
+	return ctx
+}
+
+// logRequest logs an outgoing request; headers and body are only logged at
+// trace level
+func (c *DevinClient) logRequest(ctx context.Context, req *http.Request, body []byte) {
+	tflog.SubsystemDebug(ctx, logSubsystem, "Sending Devin API request", map[string]interface{}{
+		"method": req.Method,
+		"url":    req.URL.String(),
+	})
+	tflog.SubsystemTrace(ctx, logSubsystem, "Devin API request details", map[string]interface{}{
+		"method":  req.Method,
+		"url":     req.URL.String(),
+		"headers": redactHeaders(req.Header),
+		"body":    c.loggedBody(body),
+	})
+}
+
+// logResponse logs a response with its latency and request ID
+func (c *DevinClient) logResponse(ctx context.Context, req *http.Request, resp *http.Response, body []byte, latency time.Duration) {
+	fields := map[string]interface{}{
+		"method":     req.Method,
+		"url":        req.URL.String(),
+		"status":     resp.StatusCode,
+		"latency_ms": latency.Milliseconds(),
+	}
+	if requestID := requestIDFromHeader(resp.Header); requestID != "" {
+		fields["request_id"] = requestID
+	}
+	if resp.StatusCode >= 400 {
+		// Error bodies are small and are what support needs to see
+		fields["body"] = redactBody(body)
+	}
+	tflog.SubsystemDebug(ctx, logSubsystem, "Received Devin API response", fields)
+
+	tflog.SubsystemTrace(ctx, logSubsystem, "Devin API response details", map[string]interface{}{
+		"status":  resp.StatusCode,
+		"headers": redactHeaders(resp.Header),
+		"body":    c.loggedBody(body),
+	})
+}
+
+// logRequestError logs a request that failed without a response
+func logRequestError(ctx context.Context, req *http.Request, err error, latency time.Duration) {
+	tflog.SubsystemDebug(ctx, logSubsystem, "Devin API request failed", map[string]interface{}{
+		"method":     req.Method,
+		"url":        req.URL.String(),
+		"latency_ms": latency.Milliseconds(),
+		"error":      err.Error(),
+	})
+}
+
+// redactHeaders flattens headers for logging, hiding credentials
+func redactHeaders(header http.Header) map[string]string {
+	result := make(map[string]string, len(header))
+	for name, values := range header {
+		if isSecretField(name) {
+			result[name] = redactedValue
+			continue
+		}
+		result[name] = strings.Join(values, ", ")
+	}
+	return result
+}
+
+// loggedBody is a body logged at trace level. It is only redacted when the
+// log line is written, so knowledge lists are not parsed and re-encoded
+// unless trace logging is enabled.
+type loggedBody struct {
+	body []byte
+	// tflog only masks string field values, so the client's secrets are
+	// masked here
+	secrets []string
+}
+
+// loggedBody returns body as a lazily redacted log field
+func (c *DevinClient) loggedBody(body []byte) loggedBody {
+	return loggedBody{body: body, secrets: []string{c.APIKey}}
+}
+
+// String redacts the body
+func (b loggedBody) String() string {
+	s := redactBody(b.body)
+	for _, secret := range b.secrets {
+		if secret != "" {
+			s = strings.ReplaceAll(s, secret, redactedValue)
+		}
+	}
+	return s
+}
+
+// MarshalJSON writes the redacted body as a JSON string, for JSON logs
+func (b loggedBody) MarshalJSON() ([]byte, error) {
+	return json.Marshal(b.String())
+}
+
+// redactBody prepares a body for logging: secret-looking JSON fields are
+// replaced and the result is truncated to about maxLoggedBodySize bytes,
+// without splitting a UTF-8 character
+func redactBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err == nil {
+		if redacted, err := json.Marshal(redactJSON(v)); err == nil {
+			body = redacted
+		}
+	}
+
+	if len(body) > maxLoggedBodySize {
+		cut := maxLoggedBodySize
+		for cut > 0 && !utf8.RuneStart(body[cut]) {
+			cut--
+		}
+		return fmt.Sprintf("%s... (%d bytes truncated)", body[:cut], len(body)-cut)
+	}
+	return string(body)
+}
+
+// redactJSON replaces the values of secret-looking fields at any depth
+func redactJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if isSecretField(key) {
+				v[key] = redactedValue
+			} else {
+				v[key] = redactJSON(value)
+			}
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = redactJSON(v[i])
+		}
+	}
+	return v
+}
+
+// isSecretField reports whether a header or JSON field name looks like it
+// holds a credential
+func isSecretField(name string) bool {
+	name = strings.ToLower(name)
+	for _, marker := range secretFieldMarkers {
+		if strings.Contains(name, marker) {
+			return true
+		}
+	}
+	return false
+}
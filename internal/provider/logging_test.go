@@ -0,0 +1,74 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+)
+
+func TestSendRequest_LogsRedactedTraffic(t *testing.T) {
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+
+	client := newStubClient(t, func(req *http.Request) (*http.Response, error) {
+		resp := stubResponse(http.StatusBadRequest, `{"error":{"message":"bad name","type":"invalid_request_error"},"access_token":"leaked"}`)
+		resp.Header.Set("X-Request-Id", "req-123")
+		return resp, nil
+	})
+	client.APIKey = "super-secret-key"
+
+	_, err := client.sendRequest(ctx, http.MethodPost, "/knowledge", map[string]string{
+		"name":          "Knowledge",
+		"client_secret": "hunter2",
+		"note":          "uses super-secret-key inline",
+	})
+	if err == nil {
+		t.Fatal("sendRequest() should fail on 400")
+	}
+
+	logs := output.String()
+	for _, want := range []string{"Sending Devin API request", "Received Devin API response", `"status":400`, `"request_id":"req-123"`, "latency_ms", "bad name", `"@module":"provider.devin_api"`} {
+		if !strings.Contains(logs, want) {
+			t.Errorf("logs do not contain %s:\n%s", want, logs)
+		}
+	}
+	for _, secret := range []string{"super-secret-key", "hunter2", "leaked"} {
+		if strings.Contains(logs, secret) {
+			t.Errorf("logs leak %q:\n%s", secret, logs)
+		}
+	}
+}
+
+func TestRedactBody_Truncates(t *testing.T) {
+	body := bytes.Repeat([]byte("a"), maxLoggedBodySize+10)
+	got := redactBody(body)
+	if !strings.HasSuffix(got, "(10 bytes truncated)") || len(got) > maxLoggedBodySize+30 {
+		t.Errorf("redactBody() = %q..., want a truncated body", got[:20])
+	}
+}
+
+func TestRedactBody_TruncatesOnRuneBoundary(t *testing.T) {
+	// Three-byte characters put the byte limit inside a character
+	body := []byte(strings.Repeat("あ", maxLoggedBodySize))
+	got := redactBody(body)
+	if !utf8.ValidString(got) {
+		t.Errorf("redactBody() split a UTF-8 character: %q", got[len(got)-40:])
+	}
+}
+
+func TestLoggedBody_MasksSecretsInJSON(t *testing.T) {
+	body := loggedBody{body: []byte(`{"note":"key super-secret-key inline"}`), secrets: []string{"super-secret-key"}}
+	data, err := json.Marshal(map[string]interface{}{"body": body})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if strings.Contains(string(data), "super-secret-key") || !strings.Contains(string(data), "inline") {
+		t.Errorf("logged body = %s, want the secret masked", data)
+	}
+}
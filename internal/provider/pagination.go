@@ -0,0 +1,181 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// knowledgePage is a single page of the knowledge list endpoint.
+// The API may paginate with a cursor (next_cursor) or with offsets
+// (has_more / total); unpaginated responses carry neither.
+type knowledgePage struct {
+	ListKnowledgeResponse
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more,omitempty"`
+	Total      *int   `json:"total,omitempty"`
+}
+
+// KnowledgePageIterator walks the knowledge list one page at a time.
+// Pages are fetched directly from the API and bypass the cache.
+//
+//	it := client.KnowledgePages()
+//	for it.Next(ctx) {
+//		page := it.Page()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type KnowledgePageIterator struct {
+	client   *DevinClient
+	pageSize int
+
+	cursor      string
+	offset      int
+	seenCursors map[string]bool
+
+	page  *ListKnowledgeResponse
+	pages int
+	err   error
+	done  bool
+
+	// Validators sent with the first request, the ones returned by it, and
+	// whether the server answered 304 Not Modified
+	conditional knowledgeValidators
+	validators  knowledgeValidators
+	notModified bool
+}
+
+// KnowledgePages returns an iterator over the pages of the knowledge list,
+// using the client's PageSize
+func (c *DevinClient) KnowledgePages() *KnowledgePageIterator {
+	return &KnowledgePageIterator{
+		client:      c,
+		pageSize:    c.PageSize,
+		seenCursors: make(map[string]bool),
+	}
+}
+
+// Next fetches the next page and reports whether one was available
+func (it *KnowledgePageIterator) Next(ctx context.Context) bool {
+	if it.done || it.err != nil {
+		return false
+	}
+
+	query := url.Values{}
+	if it.pageSize > 0 {
+		query.Set("limit", strconv.Itoa(it.pageSize))
+	}
+	if it.cursor != "" {
+		query.Set("cursor", it.cursor)
+	} else if it.offset > 0 {
+		query.Set("offset", strconv.Itoa(it.offset))
+	}
+
+	path := "/knowledge"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	var opts []requestOption
+	var resp *http.Response
+	if it.pages == 0 {
+		opts = append(it.conditional.requestOptions(), withResponse(&resp))
+	}
+
+	respBody, err := it.client.sendRequest(ctx, "GET", path, nil, opts...)
+	if err != nil {
+		it.err = err
+		return false
+	}
+
+	if resp != nil {
+		if resp.StatusCode == http.StatusNotModified {
+			it.notModified = true
+			it.done = true
+			return false
+		}
+		it.validators = validatorsFromHeader(resp.Header)
+	}
+	it.pages++
+
+	var page knowledgePage
+	if err := it.client.decodeResponse(ctx, respBody, &page); err != nil {
+		it.err = fmt.Errorf("failed to decode JSON response: %w", err)
+		return false
+	}
+
+	it.page = &page.ListKnowledgeResponse
+	it.offset += len(page.Knowledge)
+
+	switch {
+	case page.NextCursor != "":
+		if it.seenCursors[page.NextCursor] {
+			it.err = fmt.Errorf("knowledge list pagination returned cursor %q twice", page.NextCursor)
+			return false
+		}
+		it.seenCursors[page.NextCursor] = true
+		it.cursor = page.NextCursor
+	case page.Total != nil:
+		it.done = it.offset >= *page.Total || len(page.Knowledge) == 0
+	case page.HasMore:
+		// An empty page would make no progress
+		it.done = len(page.Knowledge) == 0
+	default:
+		it.done = true
+	}
+
+	return true
+}
+
+// Page returns the page fetched by the last successful call to Next
+func (it *KnowledgePageIterator) Page() *ListKnowledgeResponse {
+	return it.page
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *KnowledgePageIterator) Err() error {
+	return it.err
+}
+
+// fetchAllKnowledgePages downloads every page and merges them into one list.
+// Folders repeated across pages are kept once.
+//
+// The first request is made conditional on the given validators; when the
+// server answers 304 Not Modified, notModified is set and the list is nil.
+// Validators are only returned for single-page lists, since those of the
+// first page say nothing about the others.
+func (c *DevinClient) fetchAllKnowledgePages(ctx context.Context, conditional knowledgeValidators) (response *ListKnowledgeResponse, validators knowledgeValidators, notModified bool, err error) {
+	response = &ListKnowledgeResponse{
+		Knowledge: []KnowledgeItem{},
+		Folders:   []FolderItem{},
+	}
+	seenFolders := make(map[string]bool)
+
+	it := c.KnowledgePages()
+	it.conditional = conditional
+	for it.Next(ctx) {
+		page := it.Page()
+		response.Knowledge = append(response.Knowledge, page.Knowledge...)
+		for _, folder := range page.Folders {
+			if !seenFolders[folder.ID] {
+				seenFolders[folder.ID] = true
+				response.Folders = append(response.Folders, folder)
+			}
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, knowledgeValidators{}, false, err
+	}
+	if it.notModified {
+		return nil, knowledgeValidators{}, true, nil
+	}
+	if it.pages == 1 {
+		validators = it.validators
+	}
+
+	return response, validators, false, nil
+}
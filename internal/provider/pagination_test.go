@@ -0,0 +1,139 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// newPaginatedServer serves count knowledge items, paginated either with a
+// cursor or with offset/total depending on mode
+func newPaginatedServer(t *testing.T, count int, mode string) *httptest.Server {
+	t.Helper()
+
+	items := make([]KnowledgeItem, count)
+	for i := range items {
+		items[i] = KnowledgeItem{ID: fmt.Sprintf("k%d", i+1), Name: fmt.Sprintf("Knowledge %d", i+1)}
+	}
+	folders := []FolderItem{{ID: "f1", Name: "Folder 1"}}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/knowledge" {
+			http.NotFound(w, r)
+			return
+		}
+
+		limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+		if err != nil {
+			t.Errorf("request without a valid limit: %s", r.URL)
+			limit = count
+		}
+
+		start := 0
+		switch mode {
+		case "cursor":
+			if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+				start, _ = strconv.Atoi(cursor)
+			}
+		case "offset":
+			start, _ = strconv.Atoi(r.URL.Query().Get("offset"))
+		}
+		end := min(start+limit, count)
+
+		page := map[string]interface{}{
+			"knowledge": items[start:end],
+			// Folders are repeated on every page
+			"folders": folders,
+		}
+		switch mode {
+		case "cursor":
+			if end < count {
+				page["next_cursor"] = strconv.Itoa(end)
+			}
+		case "offset":
+			page["total"] = count
+		}
+		// The ETag only describes this page
+		w.Header().Set("ETag", fmt.Sprintf(`"page-%d"`, start))
+		json.NewEncoder(w).Encode(page)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestListKnowledge_Pagination(t *testing.T) {
+	for _, mode := range []string{"cursor", "offset"} {
+		t.Run(mode, func(t *testing.T) {
+			server := newPaginatedServer(t, 5, mode)
+			client, err := NewClient("key", server.URL)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+			client.PageSize = 2
+
+			ctx := context.Background()
+			response, err := client.ListKnowledge(ctx)
+			if err != nil {
+				t.Fatalf("ListKnowledge() error = %v", err)
+			}
+			if len(response.Knowledge) != 5 {
+				t.Errorf("ListKnowledge() returned %d items, want 5", len(response.Knowledge))
+			}
+			if len(response.Folders) != 1 {
+				t.Errorf("ListKnowledge() returned %d folders, want 1", len(response.Folders))
+			}
+
+			// k5 is only on the third page
+			knowledge, err := client.GetKnowledge(ctx, "k5")
+			if err != nil {
+				t.Fatalf("GetKnowledge(k5) error = %v", err)
+			}
+			if knowledge.Name != "Knowledge 5" {
+				t.Errorf("GetKnowledge(k5) Name = %s, want %s", knowledge.Name, "Knowledge 5")
+			}
+		})
+	}
+}
+
+func TestKnowledgePageIterator(t *testing.T) {
+	server := newPaginatedServer(t, 5, "cursor")
+	client, err := NewClient("key", server.URL)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	client.PageSize = 2
+
+	var sizes []int
+	it := client.KnowledgePages()
+	for it.Next(context.Background()) {
+		sizes = append(sizes, len(it.Page().Knowledge))
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("KnowledgePageIterator.Err() = %v", err)
+	}
+	if fmt.Sprint(sizes) != "[2 2 1]" {
+		t.Errorf("page sizes = %v, want [2 2 1]", sizes)
+	}
+}
+
+func TestKnowledgePageIterator_RepeatedCursor(t *testing.T) {
+	client := newStubClient(t, func(req *http.Request) (*http.Response, error) {
+		return stubResponse(http.StatusOK, `{"knowledge":[{"id":"k1"}],"folders":[],"next_cursor":"same"}`), nil
+	})
+
+	it := client.KnowledgePages()
+	pages := 0
+	for it.Next(context.Background()) {
+		pages++
+	}
+	if it.Err() == nil {
+		t.Fatal("KnowledgePageIterator should stop on a repeated cursor")
+	}
+	if pages != 1 {
+		t.Errorf("iterated %d pages, want 1", pages)
+	}
+}
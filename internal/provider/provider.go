@@ -2,7 +2,10 @@ package provider
 
 import (
 	"context"
+	"fmt"
 	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/path"
@@ -17,18 +20,55 @@ import (
 type DevinProvider struct {
 	// provider version
 	version string
+	// Collects configured clients for the end-of-run usage summary; may be nil
+	usage *UsageReporter
 }
 
+var _ provider.ProviderWithValidateConfig = &DevinProvider{}
+
 // DevinProviderModel represents the provider configuration structure
 type DevinProviderModel struct {
-	APIKey types.String `tfsdk:"api_key"`
+	APIKey       types.String `tfsdk:"api_key"`
+	Endpoint     types.String `tfsdk:"endpoint"`
+	MaxRetries   types.Int64  `tfsdk:"max_retries"`
+	RetryMaxWait types.String `tfsdk:"retry_max_wait"`
+
+	RequestsPerSecond types.Float64 `tfsdk:"requests_per_second"`
+	Burst             types.Int64   `tfsdk:"burst"`
+
+	CircuitBreakerThreshold types.Int64  `tfsdk:"circuit_breaker_threshold"`
+	CircuitBreakerCooldown  types.String `tfsdk:"circuit_breaker_cooldown"`
+
+	PageSize types.Int64  `tfsdk:"page_size"`
+	CacheDir types.String `tfsdk:"cache_dir"`
+
+	StrictDecoding types.Bool `tfsdk:"strict_decoding"`
+
+	UserAgentSuffix types.String `tfsdk:"user_agent_suffix"`
+
+	ProxyURL           types.String `tfsdk:"proxy_url"`
+	CACertFile         types.String `tfsdk:"ca_cert_file"`
+	CACertPEM          types.String `tfsdk:"ca_cert_pem"`
+	ClientCert         types.String `tfsdk:"client_cert"`
+	ClientKey          types.String `tfsdk:"client_key"`
+	InsecureSkipVerify types.Bool   `tfsdk:"insecure_skip_verify"`
+
+	UsageReportPath types.String `tfsdk:"usage_report_path"`
 }
 
 // New returns a new instance of the Devin provider
 func New(version string) func() provider.Provider {
+	return NewWithUsageReporter(version, nil)
+}
+
+// NewWithUsageReporter returns a new instance of the Devin provider that
+// registers its configured clients with usage, so their API usage can be
+// reported when the provider shuts down
+func NewWithUsageReporter(version string, usage *UsageReporter) func() provider.Provider {
 	return func() provider.Provider {
 		return &DevinProvider{
 			version: version,
+			usage:   usage,
 		}
 	}
 }
@@ -48,10 +88,100 @@ func (p *DevinProvider) Schema(_ context.Context, _ provider.SchemaRequest, resp
 				Optional:    true,
 				Sensitive:   true,
 			},
+			"circuit_breaker_threshold": schema.Int64Attribute{
+				Description: "Number of consecutive failed requests (network errors and 5xx responses) after which requests to the Devin API fail immediately for circuit_breaker_cooldown, instead of each waiting to fail. Set to 0 to disable. Defaults to 5.",
+				Optional:    true,
+			},
+			"circuit_breaker_cooldown": schema.StringAttribute{
+				Description: "How long requests fail immediately once the circuit breaker opens, as a Go duration string (e.g. \"30s\", \"1m\"). A single probe request is then sent; if it succeeds, requests resume. Defaults to \"30s\".",
+				Optional:    true,
+			},
+			"cache_dir": schema.StringAttribute{
+				Description: "Directory in which to persist the knowledge list between provider runs, so that plan and apply can share it. Entries are keyed by a hash of the API key and endpoint, expire after 15 minutes and are invalidated by any change. Disabled when not set.",
+				Optional:    true,
+			},
+			"endpoint": schema.StringAttribute{
+				Description: "Base URL of the Devin API, for example a regional, enterprise or gateway endpoint. Can also be set via the DEVIN_API_URL environment variable. Defaults to \"https://api.devin.ai/v1\".",
+				Optional:    true,
+			},
+			"max_retries": schema.Int64Attribute{
+				Description: "Maximum number of retries for rate-limited (429), server error (5xx) and network failures. Set to 0 to disable retries. Defaults to 3.",
+				Optional:    true,
+			},
+			"retry_max_wait": schema.StringAttribute{
+				Description: "Maximum time to wait between two retries, as a Go duration string (e.g. \"30s\", \"1m\"). Retry-After headers from the API are honored up to this limit. Defaults to \"30s\".",
+				Optional:    true,
+			},
+			"page_size": schema.Int64Attribute{
+				Description: "Number of knowledge items requested per page when listing knowledge. Every page is fetched. Defaults to the API's page size.",
+				Optional:    true,
+			},
+			"requests_per_second": schema.Float64Attribute{
+				Description: "Maximum sustained rate of requests to the Devin API, shared across all resources and data sources. Unlimited when not set.",
+				Optional:    true,
+			},
+			"burst": schema.Int64Attribute{
+				Description: "Number of requests that may be sent at once before requests_per_second applies. Only used together with requests_per_second. Defaults to 1.",
+				Optional:    true,
+			},
+			"user_agent_suffix": schema.StringAttribute{
+				Description: "Text appended to the User-Agent header sent to the Devin API, such as a team or pipeline name, so that traffic can be attributed to it.",
+				Optional:    true,
+			},
+			"proxy_url": schema.StringAttribute{
+				Description: "URL of the proxy to send requests through (http, https or socks5). When not set, the HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables are used.",
+				Optional:    true,
+			},
+			"ca_cert_file": schema.StringAttribute{
+				Description: "Path to a PEM file of CA certificates to trust in addition to the system's, for example a corporate proxy's CA.",
+				Optional:    true,
+			},
+			"ca_cert_pem": schema.StringAttribute{
+				Description: "PEM-encoded CA certificates to trust in addition to the system's.",
+				Optional:    true,
+			},
+			"client_cert": schema.StringAttribute{
+				Description: "Client certificate for mutual TLS, as PEM content or the path to a PEM file. Requires client_key.",
+				Optional:    true,
+			},
+			"client_key": schema.StringAttribute{
+				Description: "Private key of client_cert, as PEM content or the path to a PEM file.",
+				Optional:    true,
+				Sensitive:   true,
+			},
+			"insecure_skip_verify": schema.BoolAttribute{
+				Description: "Disable TLS certificate verification. This exposes the API key to anyone able to intercept the connection; only use it for debugging. Defaults to false.",
+				Optional:    true,
+			},
+			"strict_decoding": schema.BoolAttribute{
+				Description: "Check Devin API responses for fields this provider version does not know and for missing required fields, and report each difference once per run as a warning on the resource or data source whose request found it, to catch API changes before they affect state. Responses are still used. Defaults to false.",
+				Optional:    true,
+			},
+			"usage_report_path": schema.StringAttribute{
+				Description: "Path of a JSON file to which the provider adds its API usage when it shuts down: requests, retries, 429 responses, knowledge cache hits and misses, and bytes sent and received. Each provider process adds its counters to those already in the file, so the plan and apply of a run are summed; delete the file to start over. The summary is always logged at info level.",
+				Optional:    true,
+			},
 		},
 	}
 }
 
+// ValidateConfig warns about provider attributes that have no effect on their own
+func (p *DevinProvider) ValidateConfig(ctx context.Context, req provider.ValidateConfigRequest, resp *provider.ValidateConfigResponse) {
+	var config DevinProviderModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !config.Burst.IsNull() && config.RequestsPerSecond.IsNull() {
+		resp.Diagnostics.AddAttributeWarning(
+			path.Root("burst"),
+			"burst has no effect",
+			"burst only applies to the client-side rate limit, which is disabled because requests_per_second is not set. Set requests_per_second or remove burst.",
+		)
+	}
+}
+
 // Configure configures the provider
 func (p *DevinProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
 	tflog.Info(ctx, "Starting Devin provider configuration")
@@ -78,11 +208,185 @@ func (p *DevinProvider) Configure(ctx context.Context, req provider.ConfigureReq
 		return
 	}
 
+	// Setting API endpoint
+	endpoint := os.Getenv("DEVIN_API_URL")
+	if !config.Endpoint.IsNull() {
+		endpoint = config.Endpoint.ValueString()
+	}
+
 	// Create client
-	client := NewClient(apiKey)
+	client, err := NewClient(apiKey, endpoint)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("endpoint"),
+			"Invalid endpoint",
+			fmt.Sprintf("The endpoint must be an absolute http or https URL: %s", err),
+		)
+		return
+	}
+
+	// Proxy and TLS settings
+	transportConfig := TransportConfig{
+		ProxyURL:           config.ProxyURL.ValueString(),
+		CACertFile:         config.CACertFile.ValueString(),
+		CACertPEM:          config.CACertPEM.ValueString(),
+		ClientCert:         config.ClientCert.ValueString(),
+		ClientKey:          config.ClientKey.ValueString(),
+		InsecureSkipVerify: config.InsecureSkipVerify.ValueBool(),
+	}
+	if err := client.ConfigureTransport(transportConfig); err != nil {
+		resp.Diagnostics.AddError(
+			"Invalid proxy or TLS settings",
+			err.Error(),
+		)
+		return
+	}
+	if transportConfig.InsecureSkipVerify {
+		resp.Diagnostics.AddAttributeWarning(
+			path.Root("insecure_skip_verify"),
+			"TLS certificate verification is disabled",
+			"insecure_skip_verify is true, so the provider accepts any certificate presented by the Devin API or a proxy. "+
+				"The API key can be intercepted by anyone on the network path. Do not use this setting outside of debugging.",
+		)
+	}
+
+	// Identify the provider, its version and Terraform's version to the API
+	client.UserAgent = userAgent(p.version, req.TerraformVersion, config.UserAgentSuffix.ValueString())
+
+	// Retry settings
+	if !config.MaxRetries.IsNull() {
+		maxRetries := config.MaxRetries.ValueInt64()
+		if maxRetries < 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("max_retries"),
+				"Invalid max_retries",
+				fmt.Sprintf("max_retries must be 0 or greater, got: %d", maxRetries),
+			)
+			return
+		}
+		client.MaxRetries = int(maxRetries)
+	}
+	if !config.RetryMaxWait.IsNull() {
+		retryMaxWait, err := time.ParseDuration(config.RetryMaxWait.ValueString())
+		if err != nil || retryMaxWait <= 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("retry_max_wait"),
+				"Invalid retry_max_wait",
+				fmt.Sprintf("retry_max_wait must be a positive duration such as \"30s\", got: %q", config.RetryMaxWait.ValueString()),
+			)
+			return
+		}
+		client.RetryMaxWait = retryMaxWait
+	}
+
+	// Circuit breaker
+	if !config.CircuitBreakerThreshold.IsNull() || !config.CircuitBreakerCooldown.IsNull() {
+		threshold := int64(defaultCircuitThreshold)
+		if !config.CircuitBreakerThreshold.IsNull() {
+			threshold = config.CircuitBreakerThreshold.ValueInt64()
+			if threshold < 0 {
+				resp.Diagnostics.AddAttributeError(
+					path.Root("circuit_breaker_threshold"),
+					"Invalid circuit_breaker_threshold",
+					fmt.Sprintf("circuit_breaker_threshold must be 0 or greater, got: %d", threshold),
+				)
+				return
+			}
+		}
+		cooldown := defaultCircuitCooldown
+		if !config.CircuitBreakerCooldown.IsNull() {
+			var err error
+			cooldown, err = time.ParseDuration(config.CircuitBreakerCooldown.ValueString())
+			if err != nil || cooldown <= 0 {
+				resp.Diagnostics.AddAttributeError(
+					path.Root("circuit_breaker_cooldown"),
+					"Invalid circuit_breaker_cooldown",
+					fmt.Sprintf("circuit_breaker_cooldown must be a positive duration such as \"30s\", got: %q", config.CircuitBreakerCooldown.ValueString()),
+				)
+				return
+			}
+		}
+		client.SetCircuitBreaker(int(threshold), cooldown)
+	}
+
+	// Client-side rate limit
+	if !config.RequestsPerSecond.IsNull() {
+		requestsPerSecond := config.RequestsPerSecond.ValueFloat64()
+		if requestsPerSecond <= 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("requests_per_second"),
+				"Invalid requests_per_second",
+				fmt.Sprintf("requests_per_second must be greater than 0, got: %g", requestsPerSecond),
+			)
+			return
+		}
+
+		burst := int64(1)
+		if !config.Burst.IsNull() {
+			burst = config.Burst.ValueInt64()
+			if burst < 1 {
+				resp.Diagnostics.AddAttributeError(
+					path.Root("burst"),
+					"Invalid burst",
+					fmt.Sprintf("burst must be 1 or greater, got: %d", burst),
+				)
+				return
+			}
+		}
+		client.SetRateLimit(requestsPerSecond, int(burst))
+	}
+
+	// Pagination
+	if !config.PageSize.IsNull() {
+		pageSize := config.PageSize.ValueInt64()
+		if pageSize < 1 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("page_size"),
+				"Invalid page_size",
+				fmt.Sprintf("page_size must be 1 or greater, got: %d", pageSize),
+			)
+			return
+		}
+		client.PageSize = int(pageSize)
+	}
+
+	// Report API schema drift
+	client.StrictDecoding = config.StrictDecoding.ValueBool()
+
+	// Persistent cache
+	if !config.CacheDir.IsNull() && config.CacheDir.ValueString() != "" {
+		if err := client.EnableDiskCache(config.CacheDir.ValueString(), defaultDiskCacheTTL); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("cache_dir"),
+				"Invalid cache_dir",
+				fmt.Sprintf("Unable to use the cache directory: %s", err),
+			)
+			return
+		}
+	}
+
+	// End-of-run usage summary
+	usageReportPath := config.UsageReportPath.ValueString()
+	if usageReportPath != "" {
+		if info, err := os.Stat(filepath.Dir(usageReportPath)); err != nil || !info.IsDir() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("usage_report_path"),
+				"Invalid usage_report_path",
+				fmt.Sprintf("The directory of the usage report must exist: %s", filepath.Dir(usageReportPath)),
+			)
+			return
+		}
+	}
+	if p.usage != nil {
+		p.usage.register(ctx, client, usageReportPath)
+	}
+
+	// Resources and data sources only see the DevinAPI interface, wrapped
+	// with logging
+	api := NewLoggingAPI(client)
 
-	resp.ResourceData = client
-	resp.DataSourceData = client
+	resp.ResourceData = api
+	resp.DataSourceData = api
 
 	tflog.Info(ctx, "Devin provider configuration completed")
 }
@@ -1,17 +1,22 @@
 package provider
 
 import (
+	"context"
+	"errors"
+	"net/http"
 	"testing"
 )
 
 func TestClientError(t *testing.T) {
 	// Test with invalid API key
-	client := NewClient("invalid_key")
+	client := newStubClient(t, func(req *http.Request) (*http.Response, error) {
+		return stubResponse(http.StatusUnauthorized, `{"error":{"message":"invalid API key","type":"authentication_error"}}`), nil
+	})
+	client.APIKey = "invalid_key"
 
-	// In the current implementation, we need to skip this test when using mock data
-	// because errors are only triggered with actual API requests
-	if client.APIKey == "test_api_key" {
-		t.Skip("This test requires connection to the actual API")
+	_, err := client.ListKnowledge(context.Background())
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("ListKnowledge() error = %v, want ErrUnauthorized", err)
 	}
 }
 
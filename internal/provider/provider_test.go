@@ -6,8 +6,33 @@ import (
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 )
 
+// newProviderConfig returns a provider configuration with the given
+// attributes set and every other attribute null
+func newProviderConfig(t *testing.T, values map[string]tftypes.Value) tfsdk.Config {
+	t.Helper()
+	ctx := context.Background()
+
+	var schemaResp provider.SchemaResponse
+	(&DevinProvider{}).Schema(ctx, provider.SchemaRequest{}, &schemaResp)
+
+	objectType := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+	attributes := make(map[string]tftypes.Value, len(objectType.AttributeTypes))
+	for name, attrType := range objectType.AttributeTypes {
+		attributes[name] = tftypes.NewValue(attrType, nil)
+	}
+	for name, value := range values {
+		attributes[name] = value
+	}
+	return tfsdk.Config{
+		Schema: schemaResp.Schema,
+		Raw:    tftypes.NewValue(objectType, attributes),
+	}
+}
+
 func TestProviderSchema(t *testing.T) {
 	ctx := context.Background()
 	req := provider.SchemaRequest{}
@@ -36,16 +61,16 @@ func TestProviderConfigure(t *testing.T) {
 func TestProviderAPI(t *testing.T) {
 	// Test behavior when API_KEY is set in environment variables
 	oldAPIKey := os.Getenv("DEVIN_API_KEY")
-	os.Setenv("DEVIN_API_KEY", "test_api_key")
+	os.Setenv("DEVIN_API_KEY", "test-api-key")
 	defer os.Setenv("DEVIN_API_KEY", oldAPIKey) // Restore original value after test
 
 	// Verify that the client is created correctly
-	client := NewClient("test_api_key")
+	client := newTestClient(t, "test-api-key")
 	if client == nil {
 		t.Fatalf("NewClient() returned nil")
 	}
-	if client.APIKey != "test_api_key" {
-		t.Errorf("NewClient() API key = %s, want %s", client.APIKey, "test_api_key")
+	if client.APIKey != "test-api-key" {
+		t.Errorf("NewClient() API key = %s, want %s", client.APIKey, "test-api-key")
 	}
 }
 
@@ -86,3 +111,31 @@ func TestProviderDataSources(t *testing.T) {
 		t.Fatalf("DataSources() returned %d data sources, want 2", len(dataSources))
 	}
 }
+
+func TestProviderValidateConfig_BurstRequiresRate(t *testing.T) {
+	tests := []struct {
+		name        string
+		values      map[string]tftypes.Value
+		wantWarning bool
+	}{
+		{"burst only", map[string]tftypes.Value{"burst": tftypes.NewValue(tftypes.Number, 50)}, true},
+		{"burst and rate", map[string]tftypes.Value{
+			"burst":               tftypes.NewValue(tftypes.Number, 50),
+			"requests_per_second": tftypes.NewValue(tftypes.Number, 5),
+		}, false},
+		{"neither", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp provider.ValidateConfigResponse
+			(&DevinProvider{}).ValidateConfig(context.Background(), provider.ValidateConfigRequest{Config: newProviderConfig(t, tt.values)}, &resp)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("ValidateConfig() unexpected error: %v", resp.Diagnostics)
+			}
+			if got := resp.Diagnostics.WarningsCount() > 0; got != tt.wantWarning {
+				t.Errorf("ValidateConfig() warning = %v, want %v", got, tt.wantWarning)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"golang.org/x/time/rate"
+)
+
+// Waits shorter than this are not worth a log entry
+const rateLimitLogThreshold = 10 * time.Millisecond
+
+// SetRateLimit configures the client-side token bucket shared by every request.
+// A requestsPerSecond of 0 or less removes the limit.
+func (c *DevinClient) SetRateLimit(requestsPerSecond float64, burst int) {
+	if requestsPerSecond <= 0 {
+		c.limiter = rate.NewLimiter(rate.Inf, 0)
+		return
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	c.limiter = rate.NewLimiter(rate.Limit(requestsPerSecond), burst)
+}
+
+// waitForRateLimit blocks until the limiter allows another request
+func (c *DevinClient) waitForRateLimit(ctx context.Context, method, path string) error {
+	if c.limiter == nil {
+		return nil
+	}
+
+	start := time.Now()
+	if err := c.limiter.Wait(ctx); err != nil {
+		return fmt.Errorf("client-side rate limit wait aborted: %w", err)
+	}
+
+	if waited := time.Since(start); waited >= rateLimitLogThreshold {
+		tflog.Debug(ctx, "Waited for client-side rate limiter", map[string]interface{}{
+			"method": method,
+			"path":   path,
+			"wait":   waited.String(),
+		})
+	}
+	return nil
+}
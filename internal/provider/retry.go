@@ -0,0 +1,178 @@
+package provider
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// Default number of retries for transient API failures
+	defaultMaxRetries = 3
+	// Default upper bound for a single wait between retries
+	defaultRetryMaxWait = 30 * time.Second
+	// Default initial backoff, doubled on every attempt
+	defaultRetryMinWait = 1 * time.Second
+)
+
+// requestOptions holds per-call settings for sendRequest
+type requestOptions struct {
+	// retryNonIdempotent allows retrying POST and other non-idempotent methods
+	// on 5xx responses and network errors
+	retryNonIdempotent bool
+	// header holds extra request headers
+	header http.Header
+	// response receives the final HTTP response; its body is already consumed
+	response **http.Response
+	// mayBeApplied is set when an attempt reached the API without a clear
+	// answer, so the request may have taken effect even if it failed
+	mayBeApplied *bool
+}
+
+// requestOption customizes a single sendRequest call
+type requestOption func(*requestOptions)
+
+// withNonIdempotentRetry opts a non-idempotent request into the same retry
+// policy as idempotent ones. Only use it when repeating the request is safe.
+func withNonIdempotentRetry() requestOption {
+	return func(o *requestOptions) {
+		o.retryNonIdempotent = true
+	}
+}
+
+// withHeader adds a request header
+func withHeader(key, value string) requestOption {
+	return func(o *requestOptions) {
+		if o.header == nil {
+			o.header = make(http.Header)
+		}
+		o.header.Set(key, value)
+	}
+}
+
+// withResponse stores the final HTTP response in dst, so callers can read its
+// status code and headers
+func withResponse(dst **http.Response) requestOption {
+	return func(o *requestOptions) {
+		o.response = dst
+	}
+}
+
+// withMayBeApplied sets *dst when the request may have taken effect although
+// it failed: an attempt got a network error or a 5xx response. Requests
+// stopped by the circuit breaker or the rate limiter, and requests the API
+// rejected with a 4xx response, leave it false.
+func withMayBeApplied(dst *bool) requestOption {
+	return func(o *requestOptions) {
+		o.mayBeApplied = dst
+	}
+}
+
+// isIdempotentMethod reports whether repeating a request with the method is safe
+func isIdempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// isRetryableStatus reports whether a response status code should be retried.
+// 429 means the server rejected the request without processing it, so it is
+// safe to retry for any method; 5xx responses are only retried when the
+// request itself may be repeated.
+func isRetryableStatus(statusCode int, retryAll bool) bool {
+	if statusCode == http.StatusTooManyRequests {
+		return true
+	}
+	if !retryAll {
+		return false
+	}
+	return statusCode >= 500 && statusCode != http.StatusNotImplemented
+}
+
+// backoff returns the exponential backoff with jitter for the given attempt
+// (0-based), capped at RetryMaxWait
+func (c *DevinClient) backoff(attempt int) time.Duration {
+	wait := c.retryMinWait << uint(attempt)
+	if wait <= 0 || wait > c.RetryMaxWait {
+		wait = c.RetryMaxWait
+	}
+	if wait <= 0 {
+		return 0
+	}
+	// Equal jitter: wait somewhere between half and the full backoff
+	half := wait / 2
+	return half + rand.N(half+1)
+}
+
+// retryWait decides how long to wait before the next attempt, preferring
+// any hint the server gave through Retry-After or rate limit headers
+func (c *DevinClient) retryWait(attempt int, header http.Header) time.Duration {
+	if hint, ok := retryAfterFromHeader(header, time.Now()); ok {
+		if hint > c.RetryMaxWait {
+			return c.RetryMaxWait
+		}
+		return hint
+	}
+	return c.backoff(attempt)
+}
+
+// retryAfterFromHeader extracts the server-requested wait from Retry-After
+// (seconds or HTTP date) or, when the rate limit is exhausted, from the
+// X-RateLimit-Reset / RateLimit-Reset headers
+func retryAfterFromHeader(header http.Header, now time.Time) (time.Duration, bool) {
+	if header == nil {
+		return 0, false
+	}
+
+	if v := header.Get("Retry-After"); v != "" {
+		if seconds, err := strconv.Atoi(v); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second, true
+		}
+		if t, err := http.ParseTime(v); err == nil {
+			if d := t.Sub(now); d > 0 {
+				return d, true
+			}
+			return 0, true
+		}
+	}
+
+	for _, prefix := range []string{"X-RateLimit-", "RateLimit-"} {
+		if header.Get(prefix+"Remaining") != "0" {
+			continue
+		}
+		reset, err := strconv.ParseInt(header.Get(prefix+"Reset"), 10, 64)
+		if err != nil || reset < 0 {
+			continue
+		}
+		// Large values are Unix timestamps, small ones are seconds from now
+		if reset > 1_000_000_000 {
+			if d := time.Unix(reset, 0).Sub(now); d > 0 {
+				return d, true
+			}
+			return 0, true
+		}
+		return time.Duration(reset) * time.Second, true
+	}
+
+	return 0, false
+}
+
+// sleepContext waits for the given duration or until the context is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,246 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// schemaDrift remembers the differences between API responses and the
+// response types that were already reported, so each is reported once per
+// client
+type schemaDrift struct {
+	mu   sync.Mutex
+	seen map[string]bool
+}
+
+// schemaWarnings collects the schema differences found by the requests of
+// one Terraform operation. It travels in the operation's context, so a
+// difference is reported by the operation whose request found it.
+type schemaWarnings struct {
+	mu     sync.Mutex
+	issues []string
+	// Set once the operation has reported its warnings; later differences,
+	// e.g. from a background refresh it started, are left for another
+	// operation to report
+	closed bool
+}
+
+// schemaWarningsKey is the context key of an operation's schemaWarnings
+type schemaWarningsKey struct{}
+
+// withSchemaWarnings returns a context whose requests report their schema
+// differences to the returned collector
+func withSchemaWarnings(ctx context.Context) (context.Context, *schemaWarnings) {
+	warnings := &schemaWarnings{}
+	return context.WithValue(ctx, schemaWarningsKey{}, warnings), warnings
+}
+
+// add records issues, reporting false if the operation already finished
+func (w *schemaWarnings) add(issues []string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return false
+	}
+	w.issues = append(w.issues, issues...)
+	return true
+}
+
+// close stops collecting and returns the recorded issues
+func (w *schemaWarnings) close() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+	return w.issues
+}
+
+// decodeResponse decodes a JSON response body into v. With StrictDecoding,
+// the body is also checked against the type of v, and unknown or missing
+// fields are reported to the operation that made the request instead of
+// failing it.
+func (c *DevinClient) decodeResponse(ctx context.Context, data []byte, v interface{}) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		return err
+	}
+	if c.StrictDecoding {
+		c.drift.check(ctx, data, reflect.TypeOf(v))
+	}
+	return nil
+}
+
+// check compares a JSON document with the type it was decoded into and
+// reports new differences to the operation in ctx, if any
+func (d *schemaDrift) check(ctx context.Context, data []byte, t reflect.Type) {
+	warnings, ok := ctx.Value(schemaWarningsKey{}).(*schemaWarnings)
+	if !ok {
+		return
+	}
+
+	var issues []string
+	if issue := unknownField(data, t); issue != "" {
+		issues = append(issues, issue)
+	}
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err == nil {
+		missingFields("", doc, t, &issues)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var fresh []string
+	for _, issue := range issues {
+		if !d.seen[issue] {
+			fresh = append(fresh, issue)
+		}
+	}
+	if len(fresh) == 0 || !warnings.add(fresh) {
+		return
+	}
+
+	if d.seen == nil {
+		d.seen = make(map[string]bool)
+	}
+	for _, issue := range fresh {
+		d.seen[issue] = true
+		tflog.Warn(ctx, "Devin API response does not match the expected schema", map[string]interface{}{
+			"issue": issue,
+		})
+	}
+}
+
+// unknownField decodes data a second time, into a new value of type t,
+// rejecting fields t does not have. encoding/json stops at the first
+// unknown field, so at most one is reported per response.
+func unknownField(data []byte, t reflect.Type) string {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(reflect.New(t.Elem()).Interface())
+	if err == nil {
+		return ""
+	}
+	// encoding/json has no error type for unknown fields
+	if msg, ok := strings.CutPrefix(err.Error(), "json: "); ok && strings.HasPrefix(msg, "unknown field ") {
+		return msg
+	}
+	return ""
+}
+
+// missingFields appends the fields of type t marked with the
+// `strict:"required"` tag that value lacks to issues. Fields are named by
+// their JSON path, e.g. "knowledge[].body".
+func missingFields(path string, value interface{}, t reflect.Type, issues *[]string) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Slice:
+		items, ok := value.([]interface{})
+		if !ok {
+			return
+		}
+		for _, item := range items {
+			missingFields(path+"[]", item, t.Elem(), issues)
+		}
+	case reflect.Struct:
+		obj, ok := value.(map[string]interface{})
+		if !ok || t == reflect.TypeOf(time.Time{}) {
+			return
+		}
+		fields := make(map[string]schemaField)
+		collectSchemaFields(t, fields)
+
+		var found []string
+		for name, field := range fields {
+			child, ok := lookupJSONKey(obj, name)
+			if !ok {
+				if field.required {
+					found = append(found, fmt.Sprintf("missing field %q", joinSchemaPath(path, name)))
+				}
+				continue
+			}
+			missingFields(joinSchemaPath(path, name), child, field.typ, issues)
+		}
+		// Map iteration order is random; keep reports stable
+		sort.Strings(found)
+		*issues = append(*issues, found...)
+	}
+}
+
+// schemaField is a JSON field of a response type
+type schemaField struct {
+	typ      reflect.Type
+	required bool
+}
+
+// collectSchemaFields adds the JSON fields of struct type t to fields,
+// including those of embedded structs
+func collectSchemaFields(t reflect.Type, fields map[string]schemaField) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			collectSchemaFields(f.Type, fields)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = schemaField{typ: f.Type, required: f.Tag.Get("strict") == "required"}
+	}
+}
+
+// lookupJSONKey finds the value a field decodes from, preferring an exact
+// key match but, like encoding/json, accepting a case-insensitive one
+func lookupJSONKey(obj map[string]interface{}, name string) (interface{}, bool) {
+	if value, ok := obj[name]; ok {
+		return value, true
+	}
+	for key, value := range obj {
+		if strings.EqualFold(key, name) {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// joinSchemaPath appends a field name to a JSON path
+func joinSchemaPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// addSchemaWarnings reports the schema differences collected for an
+// operation as a single warning diagnostic
+func addSchemaWarnings(warnings *schemaWarnings, diags *diag.Diagnostics) {
+	issues := warnings.close()
+	if len(issues) == 0 {
+		return
+	}
+	diags.AddWarning(
+		"Devin API responses do not match the expected schema",
+		fmt.Sprintf("strict_decoding found differences between the Devin API responses and what this provider version expects:\n\n- %s\n\n"+
+			"The responses were still used; unknown fields are ignored and missing fields are left empty. "+
+			"The API may have changed: check for a newer provider version before the differences affect your state.",
+			strings.Join(issues, "\n- ")),
+	)
+}
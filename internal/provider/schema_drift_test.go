@@ -0,0 +1,122 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+)
+
+// driftedListBody is a knowledge list with a new top-level field, a new
+// knowledge field, a renamed field and a differently cased known field
+const driftedListBody = `{
+	"knowledge": [
+		{"id": "k1", "name": "Knowledge", "body": "body", "trigger_description": "trigger", "tags": ["a"]},
+		{"id": "k2", "name": "Renamed", "content": "body", "trigger_description": "trigger", "Parent_Folder_ID": "f1"}
+	],
+	"folders": [{"id": "f1", "name": "Folder"}],
+	"workspace": "w1"
+}`
+
+func newDriftedClient(t *testing.T, strict bool) *DevinClient {
+	t.Helper()
+	client := newStubClient(t, func(req *http.Request) (*http.Response, error) {
+		return stubResponse(http.StatusOK, driftedListBody), nil
+	})
+	client.StrictDecoding = strict
+	return client
+}
+
+// listWithSchemaWarnings lists knowledge as one operation and returns the
+// schema differences reported to it
+func listWithSchemaWarnings(t *testing.T, client *DevinClient) []string {
+	t.Helper()
+	ctx, warnings := withSchemaWarnings(context.Background())
+	client.InvalidateCache(ctx)
+	if _, err := client.ListKnowledge(ctx); err != nil {
+		t.Fatalf("ListKnowledge() error = %v", err)
+	}
+	return warnings.close()
+}
+
+func TestStrictDecoding_ReportsDriftOnce(t *testing.T) {
+	client := newDriftedClient(t, true)
+
+	ctx, warnings := withSchemaWarnings(context.Background())
+	list, err := client.ListKnowledge(ctx)
+	if err != nil {
+		t.Fatalf("ListKnowledge() error = %v", err)
+	}
+	if len(list.Knowledge) != 2 || list.Knowledge[1].ParentFolderID != "f1" {
+		t.Errorf("ListKnowledge() = %+v, drifted responses should still be decoded", list)
+	}
+
+	// The decoder stops at the first unknown field
+	want := []string{
+		`unknown field "tags"`,
+		`missing field "knowledge[].body"`,
+	}
+	if got := warnings.close(); !reflect.DeepEqual(got, want) {
+		t.Errorf("schema warnings = %q, want %q", got, want)
+	}
+
+	// The same drift in later responses is not reported again
+	if got := listWithSchemaWarnings(t, client); len(got) != 0 {
+		t.Errorf("schema warnings = %q after a repeated response, want none", got)
+	}
+}
+
+func TestStrictDecoding_DisabledByDefault(t *testing.T) {
+	client := newDriftedClient(t, false)
+	if got := listWithSchemaWarnings(t, client); len(got) != 0 {
+		t.Errorf("schema warnings = %q, want none without strict decoding", got)
+	}
+}
+
+func TestStrictDecoding_ReportsToRequestingOperation(t *testing.T) {
+	client := newDriftedClient(t, true)
+
+	// Drift found by a request whose operation already finished, such as a
+	// background refresh, or outside any operation is left for the next one
+	finished, warnings := withSchemaWarnings(context.Background())
+	warnings.close()
+	if _, err := client.ListKnowledge(finished); err != nil {
+		t.Fatalf("ListKnowledge() error = %v", err)
+	}
+	client.InvalidateCache(context.Background())
+	if _, err := client.ListKnowledge(context.Background()); err != nil {
+		t.Fatalf("ListKnowledge() error = %v", err)
+	}
+
+	if got := listWithSchemaWarnings(t, client); len(got) != 2 {
+		t.Errorf("schema warnings = %q, want the drift reported by the next operation", got)
+	}
+}
+
+func TestStrictDecoding_WarningDiagnostic(t *testing.T) {
+	r := &KnowledgeResource{client: NewLoggingAPI(newDriftedClient(t, true))}
+	ctx := context.Background()
+
+	var warnings []string
+	for i := 0; i < 2; i++ {
+		state := newKnowledgeState(t, testKnowledgeModel("k1"))
+		resp := resource.ReadResponse{State: state}
+		r.Read(ctx, resource.ReadRequest{State: state}, &resp)
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("Read() unexpected error: %v", resp.Diagnostics)
+		}
+		for _, d := range resp.Diagnostics.Warnings() {
+			warnings = append(warnings, d.Detail())
+		}
+	}
+
+	if len(warnings) != 1 {
+		t.Fatalf("Read() reported %d schema warnings over two reads, want 1", len(warnings))
+	}
+	if !strings.Contains(warnings[0], `missing field "knowledge[].body"`) {
+		t.Errorf("warning detail = %q, want the unknown field", warnings[0])
+	}
+}
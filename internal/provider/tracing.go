@@ -0,0 +1,97 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Instrumentation scope of the provider's spans
+const tracerName = "github.com/hirosi1900day/terraform-provider-devin-knowledge/internal/provider"
+
+// spanFlushTimeout bounds how long an operation waits for its spans to be exported
+const spanFlushTimeout = time.Second
+
+// Span attribute keys; the HTTP ones follow the OpenTelemetry semantic conventions
+const (
+	attrHTTPMethod  = attribute.Key("http.request.method")
+	attrHTTPStatus  = attribute.Key("http.response.status_code")
+	attrURLPath     = attribute.Key("url.path")
+	attrRetryCount  = attribute.Key("devin.retry_count")
+	attrCacheHit    = attribute.Key("devin.cache.hit")
+	attrCacheResult = attribute.Key("devin.cache.result")
+	attrResourceID  = attribute.Key("devin.resource.id")
+)
+
+// startSpan starts a span using the global tracer provider, which does
+// nothing unless tracing was enabled through the OTEL_* environment variables
+func startSpan(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, opts...)
+}
+
+// endHandlerSpan ends the span of a Terraform operation, marking it failed
+// if the operation reported an error diagnostic, and exports the spans of
+// the operation
+func endHandlerSpan(span trace.Span, diags diag.Diagnostics) {
+	for _, d := range diags.Errors() {
+		span.SetStatus(codes.Error, d.Summary())
+		break
+	}
+	span.End()
+	flushSpans()
+}
+
+// flushSpans exports the spans ended so far. Terraform kills the provider
+// shortly after its last operation, so spans are not left for the flush
+// when the provider exits.
+func flushSpans() {
+	flusher, ok := otel.GetTracerProvider().(interface {
+		ForceFlush(ctx context.Context) error
+	})
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), spanFlushTimeout)
+	defer cancel()
+	if err := flusher.ForceFlush(ctx); err != nil {
+		otel.Handle(err)
+	}
+}
+
+// endRequestSpan ends the span of an API request, recording its error, if any
+func endRequestSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// recordCacheResult annotates the current span with how the knowledge cache
+// served a lookup: "hit", "stale" (served while refreshing) or "miss"
+func recordCacheResult(ctx context.Context, result string) {
+	trace.SpanFromContext(ctx).SetAttributes(
+		attrCacheHit.Bool(result != "miss"),
+		attrCacheResult.String(result),
+	)
+}
+
+// spanRoute returns the low-cardinality route of an API path for span names,
+// replacing the resource ID, e.g. "/knowledge/abc?x=1" becomes "/knowledge/{id}"
+func spanRoute(path string) string {
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+	segments := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	if len(segments) > 1 && segments[1] != "" {
+		segments[1] = "{id}"
+	}
+	return "/" + strings.Join(segments, "/")
+}
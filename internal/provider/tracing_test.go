@@ -0,0 +1,158 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// recordSpans installs a tracer provider recording every span for the test
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		_ = provider.Shutdown(context.Background())
+	})
+	return recorder
+}
+
+// spanAttributes returns the attributes of a span keyed by name
+func spanAttributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestTracing_ReadSpans(t *testing.T) {
+	recorder := recordSpans(t)
+	calls := 0
+	client := newStubClient(t, func(req *http.Request) (*http.Response, error) {
+		calls++
+		if calls == 1 {
+			return stubResponse(http.StatusServiceUnavailable, ""), nil
+		}
+		return stubResponse(http.StatusOK, `{"knowledge":[{"id":"k1","name":"Knowledge"}],"folders":[]}`), nil
+	})
+	r := &KnowledgeResource{client: client}
+	ctx := context.Background()
+
+	// The first read misses the cache, the second hits it
+	for i := 0; i < 2; i++ {
+		state := newKnowledgeState(t, testKnowledgeModel("k1"))
+		resp := resource.ReadResponse{State: state}
+		r.Read(ctx, resource.ReadRequest{State: state}, &resp)
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("Read() unexpected error: %v", resp.Diagnostics)
+		}
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("recorded %d spans, want 3", len(spans))
+	}
+
+	request, miss, hit := spans[0], spans[1], spans[2]
+	if request.Name() != "GET /knowledge" {
+		t.Errorf("request span name = %s, want GET /knowledge", request.Name())
+	}
+	if request.Parent().SpanID() != miss.SpanContext().SpanID() {
+		t.Error("request span should be a child of the first read")
+	}
+	attrs := spanAttributes(request)
+	if attrs[attrHTTPMethod].AsString() != "GET" || attrs[attrURLPath].AsString() != "/knowledge" {
+		t.Errorf("request span attributes = %v", attrs)
+	}
+	if attrs[attrHTTPStatus].AsInt64() != http.StatusOK || attrs[attrRetryCount].AsInt64() != 1 {
+		t.Errorf("request span status = %v, retries = %v, want 200 after 1 retry", attrs[attrHTTPStatus], attrs[attrRetryCount])
+	}
+
+	for span, want := range map[sdktrace.ReadOnlySpan]string{miss: "miss", hit: "hit"} {
+		if span.Name() != "devin_knowledge.Read" {
+			t.Errorf("handler span name = %s, want devin_knowledge.Read", span.Name())
+		}
+		attrs := spanAttributes(span)
+		if got := attrs[attrCacheResult].AsString(); got != want {
+			t.Errorf("cache result = %s, want %s", got, want)
+		}
+		if attrs[attrResourceID].AsString() != "k1" {
+			t.Errorf("resource ID = %v, want k1", attrs[attrResourceID])
+		}
+	}
+}
+
+func TestTracing_ErrorStatus(t *testing.T) {
+	recorder := recordSpans(t)
+	client := newStubClient(t, func(req *http.Request) (*http.Response, error) {
+		return stubResponse(http.StatusForbidden, `{"error":{"message":"no access","type":"forbidden"}}`), nil
+	})
+	r := &KnowledgeResource{client: client}
+
+	state := newKnowledgeState(t, testKnowledgeModel("k1"))
+	resp := resource.DeleteResponse{State: state}
+	r.Delete(context.Background(), resource.DeleteRequest{State: state}, &resp)
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("recorded %d spans, want 2", len(spans))
+	}
+	request, handler := spans[0], spans[1]
+	if request.Name() != "DELETE /knowledge/{id}" || request.Status().Code != codes.Error {
+		t.Errorf("request span = %s %v, want a failed DELETE /knowledge/{id}", request.Name(), request.Status())
+	}
+	if spanAttributes(request)[attrHTTPStatus].AsInt64() != http.StatusForbidden {
+		t.Errorf("request span status code = %v, want 403", spanAttributes(request)[attrHTTPStatus])
+	}
+	if handler.Status().Code != codes.Error || handler.Status().Description != "Failed to delete knowledge" {
+		t.Errorf("handler span status = %v, want the error diagnostic", handler.Status())
+	}
+}
+
+func TestSpanRoute(t *testing.T) {
+	tests := map[string]string{
+		"/knowledge":              "/knowledge",
+		"/knowledge?limit=10":     "/knowledge",
+		"/knowledge/note-123":     "/knowledge/{id}",
+		"/knowledge/note-123?x=1": "/knowledge/{id}",
+	}
+	for path, want := range tests {
+		if got := spanRoute(path); got != want {
+			t.Errorf("spanRoute(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestTracing_HandlerFlushesSpans(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	// The batch would only be exported after an hour without a flush
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter, sdktrace.WithBatchTimeout(time.Hour)))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		_ = provider.Shutdown(context.Background())
+	})
+
+	r := &KnowledgeResource{client: newFakeBackend()}
+	state := newKnowledgeState(t, testKnowledgeModel("missing"))
+	resp := resource.ReadResponse{State: state}
+	r.Read(context.Background(), resource.ReadRequest{State: state}, &resp)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 || spans[0].Name != "devin_knowledge.Read" {
+		t.Errorf("exported spans = %v, want the Read span exported when Read returns", spans.Snapshots())
+	}
+}
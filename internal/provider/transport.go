@@ -0,0 +1,119 @@
+package provider
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// TransportConfig holds the network settings of the HTTP client. The zero
+// value uses the system's CA bundle and the HTTP_PROXY, HTTPS_PROXY and
+// NO_PROXY environment variables.
+type TransportConfig struct {
+	// Proxy for every request, overriding the proxy environment variables
+	ProxyURL string
+	// Additional CA certificates to trust: a PEM bundle file, and PEM content
+	CACertFile string
+	CACertPEM  string
+	// Client certificate and key for mutual TLS, each as PEM content or a
+	// path to a PEM file
+	ClientCert string
+	ClientKey  string
+	// Disable TLS certificate verification; only for debugging
+	InsecureSkipVerify bool
+}
+
+// ConfigureTransport replaces the HTTP client's transport with one using cfg.
+// The transport is cloned from http.DefaultTransport, so connection pooling,
+// timeouts and environment proxy support are kept.
+func (c *DevinClient) ConfigureTransport(cfg TransportConfig) error {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if cfg.ProxyURL != "" {
+		proxyURL, err := url.Parse(cfg.ProxyURL)
+		if err != nil {
+			return fmt.Errorf("invalid proxy URL %q: %w", cfg.ProxyURL, err)
+		}
+		switch proxyURL.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return fmt.Errorf("invalid proxy URL %q: scheme must be http, https or socks5", cfg.ProxyURL)
+		}
+		if proxyURL.Host == "" {
+			return fmt.Errorf("invalid proxy URL %q: host is missing", cfg.ProxyURL)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	tlsConfig, err := cfg.tlsConfig()
+	if err != nil {
+		return err
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	c.HTTPClient.Transport = transport
+	return nil
+}
+
+// tlsConfig builds the TLS settings
+func (cfg TransportConfig) tlsConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+
+	if cfg.CACertFile != "" || cfg.CACertPEM != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if cfg.CACertFile != "" {
+			pem, err := os.ReadFile(cfg.CACertFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read CA certificate file: %w", err)
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no PEM certificates found in %s", cfg.CACertFile)
+			}
+		}
+		if cfg.CACertPEM != "" && !pool.AppendCertsFromPEM([]byte(cfg.CACertPEM)) {
+			return nil, errors.New("no PEM certificates found in the CA certificate")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.ClientCert != "" || cfg.ClientKey != "" {
+		if cfg.ClientCert == "" || cfg.ClientKey == "" {
+			return nil, errors.New("client certificate and client key must be set together")
+		}
+		certPEM, err := readPEM(cfg.ClientCert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client certificate: %w", err)
+		}
+		keyPEM, err := readPEM(cfg.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client key: %w", err)
+		}
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate or key: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// readPEM returns value itself if it is PEM content, or the content of the
+// file it names otherwise
+func readPEM(value string) ([]byte, error) {
+	if strings.Contains(value, "-----BEGIN") {
+		return []byte(value), nil
+	}
+	return os.ReadFile(value)
+}
@@ -0,0 +1,181 @@
+package provider
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// listHandler serves an empty knowledge list
+var listHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprint(w, `{"knowledge":[],"folders":[]}`)
+})
+
+// certPEM encodes a certificate as PEM
+func certPEM(cert *x509.Certificate) string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+}
+
+// newClientCertificate creates a CA and a client certificate signed by it,
+// returning the CA pool and the certificate and key as PEM
+func newClientCertificate(t *testing.T) (*x509.CertPool, string, string) {
+	t.Helper()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test client CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("CreateCertificate() error = %v", err)
+	}
+	ca, _ := x509.ParseCertificate(caDER)
+
+	clientKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	clientTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "terraform"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	clientDER, err := x509.CreateCertificate(rand.Reader, clientTemplate, ca, &clientKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("CreateCertificate() error = %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(clientKey)
+	if err != nil {
+		t.Fatalf("MarshalECPrivateKey() error = %v", err)
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+	return pool,
+		string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: clientDER})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+}
+
+// listWithTransport lists knowledge from endpoint using cfg
+func listWithTransport(t *testing.T, endpoint string, cfg TransportConfig) error {
+	t.Helper()
+	client, err := NewClient("key", endpoint)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	client.MaxRetries = 0
+	if err := client.ConfigureTransport(cfg); err != nil {
+		t.Fatalf("ConfigureTransport() error = %v", err)
+	}
+	_, err = client.ListKnowledge(context.Background())
+	return err
+}
+
+func TestConfigureTransport_CustomCA(t *testing.T) {
+	server := httptest.NewTLSServer(listHandler)
+	defer server.Close()
+	caPEM := certPEM(server.Certificate())
+
+	if err := listWithTransport(t, server.URL, TransportConfig{}); err == nil {
+		t.Error("request to a server with an untrusted certificate should fail")
+	}
+	if err := listWithTransport(t, server.URL, TransportConfig{CACertPEM: caPEM}); err != nil {
+		t.Errorf("request with ca_cert_pem error = %v", err)
+	}
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, []byte(caPEM), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := listWithTransport(t, server.URL, TransportConfig{CACertFile: caFile}); err != nil {
+		t.Errorf("request with ca_cert_file error = %v", err)
+	}
+	if err := listWithTransport(t, server.URL, TransportConfig{InsecureSkipVerify: true}); err != nil {
+		t.Errorf("request with insecure_skip_verify error = %v", err)
+	}
+}
+
+func TestConfigureTransport_ClientCertificate(t *testing.T) {
+	clientCAs, clientCert, clientKey := newClientCertificate(t)
+
+	server := httptest.NewUnstartedServer(listHandler)
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+	caPEM := certPEM(server.Certificate())
+
+	if err := listWithTransport(t, server.URL, TransportConfig{CACertPEM: caPEM}); err == nil {
+		t.Error("request without a client certificate should fail")
+	}
+
+	// The key is passed as a file path, the certificate as PEM content
+	keyFile := filepath.Join(t.TempDir(), "client.key")
+	if err := os.WriteFile(keyFile, []byte(clientKey), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	cfg := TransportConfig{CACertPEM: caPEM, ClientCert: clientCert, ClientKey: keyFile}
+	if err := listWithTransport(t, server.URL, cfg); err != nil {
+		t.Errorf("request with a client certificate error = %v", err)
+	}
+}
+
+func TestConfigureTransport_Proxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A forward proxy receives the absolute target URL
+		proxied = r.URL.String()
+		listHandler(w, r)
+	}))
+	defer proxy.Close()
+
+	if err := listWithTransport(t, "http://devin.example.invalid/v1", TransportConfig{ProxyURL: proxy.URL}); err != nil {
+		t.Fatalf("request through proxy error = %v", err)
+	}
+	if !strings.HasPrefix(proxied, "http://devin.example.invalid/v1/knowledge") {
+		t.Errorf("proxy received %q, want the Devin API URL", proxied)
+	}
+}
+
+func TestConfigureTransport_InvalidSettings(t *testing.T) {
+	tests := map[string]TransportConfig{
+		"proxy scheme":     {ProxyURL: "ftp://proxy.example.com"},
+		"proxy host":       {ProxyURL: "http://"},
+		"CA PEM":           {CACertPEM: "not a certificate"},
+		"CA file":          {CACertFile: filepath.Join(t.TempDir(), "missing.pem")},
+		"cert without key": {ClientCert: "-----BEGIN CERTIFICATE-----"},
+	}
+	for name, cfg := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := newTestClient(t, "key")
+			if err := client.ConfigureTransport(cfg); err == nil {
+				t.Error("ConfigureTransport() should return an error")
+			}
+		})
+	}
+}
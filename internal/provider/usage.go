@@ -0,0 +1,167 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// UsageStats summarizes the Devin API traffic of a client
+type UsageStats struct {
+	// HTTP requests sent, including retries
+	Requests int64 `json:"requests"`
+	// Requests that were retries of an earlier attempt
+	Retries int64 `json:"retries"`
+	// Responses with status 429 Too Many Requests
+	RateLimited int64 `json:"rate_limited"`
+	// Knowledge list lookups served from the in-memory cache, including
+	// stale snapshots served while refreshing
+	CacheHits int64 `json:"cache_hits"`
+	// Knowledge list lookups that had to wait for a fetch
+	CacheMisses int64 `json:"cache_misses"`
+	// Request and response body bytes
+	BytesSent     int64 `json:"bytes_sent"`
+	BytesReceived int64 `json:"bytes_received"`
+}
+
+// add returns the sum of two sets of counters
+func (s UsageStats) add(other UsageStats) UsageStats {
+	return UsageStats{
+		Requests:      s.Requests + other.Requests,
+		Retries:       s.Retries + other.Retries,
+		RateLimited:   s.RateLimited + other.RateLimited,
+		CacheHits:     s.CacheHits + other.CacheHits,
+		CacheMisses:   s.CacheMisses + other.CacheMisses,
+		BytesSent:     s.BytesSent + other.BytesSent,
+		BytesReceived: s.BytesReceived + other.BytesReceived,
+	}
+}
+
+// usageCounters are the live counters behind UsageStats
+type usageCounters struct {
+	requests      atomic.Int64
+	retries       atomic.Int64
+	rateLimited   atomic.Int64
+	cacheHits     atomic.Int64
+	cacheMisses   atomic.Int64
+	bytesSent     atomic.Int64
+	bytesReceived atomic.Int64
+}
+
+// Usage returns the API usage of the client so far
+func (c *DevinClient) Usage() UsageStats {
+	return UsageStats{
+		Requests:      c.usage.requests.Load(),
+		Retries:       c.usage.retries.Load(),
+		RateLimited:   c.usage.rateLimited.Load(),
+		CacheHits:     c.usage.cacheHits.Load(),
+		CacheMisses:   c.usage.cacheMisses.Load(),
+		BytesSent:     c.usage.bytesSent.Load(),
+		BytesReceived: c.usage.bytesReceived.Load(),
+	}
+}
+
+// UsageReporter collects the clients configured during a provider run and
+// reports their API usage when the provider shuts down
+type UsageReporter struct {
+	mu      sync.Mutex
+	clients []usageReportClient
+}
+
+// usageReportClient is a configured client and where to report its usage
+type usageReportClient struct {
+	// Context of the Configure call, carrying the provider logger
+	ctx    context.Context
+	client *DevinClient
+	// JSON file to write the usage to; empty to only log it
+	path string
+}
+
+// NewUsageReporter returns a reporter with no clients
+func NewUsageReporter() *UsageReporter {
+	return &UsageReporter{}
+}
+
+// register adds a configured client to the report
+func (r *UsageReporter) register(ctx context.Context, client *DevinClient, path string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.clients = append(r.clients, usageReportClient{
+		ctx:    context.WithoutCancel(ctx),
+		client: client,
+		path:   path,
+	})
+}
+
+// Report logs the usage of every registered client and adds it to the
+// usage_report_path files. Clients sharing a file are added up, and so are
+// separate provider processes, such as the plan and apply of one run, since
+// each adds its counters to those already in the file.
+func (r *UsageReporter) Report() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	totals := make(map[string]UsageStats)
+	var paths []string
+	for _, c := range r.clients {
+		usage := c.client.Usage()
+		tflog.Info(c.ctx, "Devin API usage summary", map[string]interface{}{
+			"endpoint":       c.client.BaseURL,
+			"requests":       usage.Requests,
+			"retries":        usage.Retries,
+			"rate_limited":   usage.RateLimited,
+			"cache_hits":     usage.CacheHits,
+			"cache_misses":   usage.CacheMisses,
+			"bytes_sent":     usage.BytesSent,
+			"bytes_received": usage.BytesReceived,
+		})
+
+		if c.path == "" {
+			continue
+		}
+		if _, ok := totals[c.path]; !ok {
+			paths = append(paths, c.path)
+		}
+		totals[c.path] = totals[c.path].add(usage)
+	}
+
+	var errs []error
+	for _, path := range paths {
+		if err := mergeUsageReport(path, totals[path]); err != nil {
+			errs = append(errs, fmt.Errorf("failed to write usage report %s: %w", path, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// mergeUsageReport adds usage to the counters in the report file at path,
+// holding its lock so that concurrent provider processes do not lose updates
+func mergeUsageReport(path string, usage UsageStats) error {
+	return withFileLock(context.Background(), path, func() error {
+		data, err := os.ReadFile(path)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+		case err != nil:
+			return err
+		case len(bytes.TrimSpace(data)) > 0:
+			var previous UsageStats
+			if err := json.Unmarshal(data, &previous); err != nil {
+				return fmt.Errorf("existing report is not a usage report, remove it to start over: %w", err)
+			}
+			usage = usage.add(previous)
+		}
+
+		data, err = json.MarshalIndent(usage, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode usage report: %w", err)
+		}
+		return writeFileAtomic(path, append(data, '\n'))
+	})
+}
@@ -0,0 +1,146 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+)
+
+func TestUsage_CountsRequestsAndCache(t *testing.T) {
+	const listBody = `{"knowledge":[{"id":"k1","name":"Knowledge"}],"folders":[]}`
+	var calls int32
+	client := newStubClient(t, func(req *http.Request) (*http.Response, error) {
+		if req.Method == http.MethodPost {
+			return stubResponse(http.StatusOK, `{"id":"k2"}`), nil
+		}
+		if atomic.AddInt32(&calls, 1) == 1 {
+			return stubResponse(http.StatusTooManyRequests, ""), nil
+		}
+		return stubResponse(http.StatusOK, listBody), nil
+	})
+	ctx := context.Background()
+
+	// A miss fetching the list after one 429, then a hit
+	for _, id := range []string{"k1", "k1"} {
+		if _, err := client.GetKnowledge(ctx, id); err != nil {
+			t.Fatalf("GetKnowledge() error = %v", err)
+		}
+	}
+	if _, err := client.CreateKnowledge(ctx, "Knowledge 2", "body", "trigger", ""); err != nil {
+		t.Fatalf("CreateKnowledge() error = %v", err)
+	}
+
+	createBody, _ := json.Marshal(CreateKnowledgeRequest{Name: "Knowledge 2", Body: "body", TriggerDescription: "trigger"})
+	want := UsageStats{
+		Requests:      3,
+		Retries:       1,
+		RateLimited:   1,
+		CacheHits:     1,
+		CacheMisses:   1,
+		BytesSent:     int64(len(createBody)),
+		BytesReceived: int64(len(listBody) + len(`{"id":"k2"}`)),
+	}
+	if got := client.Usage(); got != want {
+		t.Errorf("Usage() = %+v, want %+v", got, want)
+	}
+}
+
+func TestUsageReporter_Report(t *testing.T) {
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+	path := filepath.Join(t.TempDir(), "usage.json")
+
+	newClient := func() *DevinClient {
+		client := newStubClient(t, func(req *http.Request) (*http.Response, error) {
+			return stubResponse(http.StatusOK, `{"knowledge":[],"folders":[]}`), nil
+		})
+		if _, err := client.ListKnowledge(context.Background()); err != nil {
+			t.Fatalf("ListKnowledge() error = %v", err)
+		}
+		return client
+	}
+
+	// Two provider configurations writing to the same file are added up;
+	// one without a path is only logged
+	reporter := NewUsageReporter()
+	reporter.register(ctx, newClient(), path)
+	reporter.register(ctx, newClient(), path)
+	reporter.register(ctx, newClient(), "")
+	if err := reporter.Report(); err != nil {
+		t.Fatalf("Report() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if logs := output.String(); strings.Count(logs, "Devin API usage summary") != 3 {
+		t.Errorf("logs should contain a summary per client:\n%s", logs)
+	}
+
+	var got UsageStats
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("usage report is not valid JSON: %v", err)
+	}
+	if got.Requests != 2 || got.CacheMisses != 2 {
+		t.Errorf("usage report = %+v, want 2 requests and 2 cache misses", got)
+	}
+
+	reporter.register(ctx, newClient(), filepath.Join(t.TempDir(), "missing", "usage.json"))
+	if err := reporter.Report(); err == nil {
+		t.Error("Report() should fail when the report cannot be written")
+	}
+}
+
+func TestUsageReporter_ReportMergesProcesses(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.json")
+
+	// plan and apply run in separate provider processes, each with its own
+	// reporter, and report to the same file
+	for i := 0; i < 2; i++ {
+		client := newStubClient(t, func(req *http.Request) (*http.Response, error) {
+			return stubResponse(http.StatusOK, `{"knowledge":[],"folders":[]}`), nil
+		})
+		if _, err := client.ListKnowledge(context.Background()); err != nil {
+			t.Fatalf("ListKnowledge() error = %v", err)
+		}
+		reporter := NewUsageReporter()
+		reporter.register(context.Background(), client, path)
+		if err := reporter.Report(); err != nil {
+			t.Fatalf("Report() error = %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	var got UsageStats
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("usage report is not valid JSON: %v", err)
+	}
+	if got.Requests != 2 || got.CacheMisses != 2 || got.BytesReceived == 0 {
+		t.Errorf("usage report = %+v, want the counters of both processes", got)
+	}
+
+	// A file that is not a usage report is left alone
+	if err := os.WriteFile(path, []byte("not json"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	reporter := NewUsageReporter()
+	reporter.register(context.Background(), newStubClient(t, nil), path)
+	if err := reporter.Report(); err == nil {
+		t.Error("Report() should fail when the existing file is not a usage report")
+	}
+	if data, _ := os.ReadFile(path); string(data) != "not json" {
+		t.Errorf("Report() overwrote a file that is not a usage report: %q", data)
+	}
+}
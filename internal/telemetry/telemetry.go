@@ -0,0 +1,152 @@
+// Package telemetry configures OpenTelemetry tracing for the provider from
+// the standard OTEL_* environment variables.
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.43.0"
+)
+
+const (
+	// Service name reported unless OTEL_SERVICE_NAME is set
+	serviceName = "terraform-provider-devin"
+	// TracesFileEnv names the file the console exporter writes to instead of
+	// stderr; stdout is reserved for the plugin handshake
+	TracesFileEnv = "DEVIN_TRACES_FILE"
+)
+
+// ShutdownFunc flushes pending spans and releases the exporters
+type ShutdownFunc func(ctx context.Context) error
+
+// Setup installs a global tracer provider when OTEL_TRACES_EXPORTER selects
+// an exporter:
+//
+//   - "otlp" sends spans to the endpoint from OTEL_EXPORTER_OTLP_ENDPOINT,
+//     using gRPC or HTTP according to OTEL_EXPORTER_OTLP_PROTOCOL
+//   - "console" writes spans as JSON to stderr, or to the file named by
+//     DEVIN_TRACES_FILE
+//   - "none", or leaving it unset, disables tracing
+//
+// Several exporters may be given as a comma-separated list. Tracing is also
+// disabled when OTEL_SDK_DISABLED is true. The returned function must be
+// called before the process exits.
+func Setup(ctx context.Context, version string) (ShutdownFunc, error) {
+	noop := func(context.Context) error { return nil }
+
+	if strings.EqualFold(os.Getenv("OTEL_SDK_DISABLED"), "true") {
+		return noop, nil
+	}
+
+	var exporters []sdktrace.SpanExporter
+	var closers []io.Closer
+	shutdownExporters := func(ctx context.Context) error {
+		var errs []error
+		for _, exporter := range exporters {
+			errs = append(errs, exporter.Shutdown(ctx))
+		}
+		for _, closer := range closers {
+			errs = append(errs, closer.Close())
+		}
+		return errors.Join(errs...)
+	}
+
+	for _, name := range strings.Split(os.Getenv("OTEL_TRACES_EXPORTER"), ",") {
+		var exporter sdktrace.SpanExporter
+		var err error
+		switch name = strings.TrimSpace(strings.ToLower(name)); name {
+		case "", "none":
+			continue
+		case "otlp":
+			exporter, err = newOTLPExporter(ctx)
+		case "console":
+			var w io.Writer = os.Stderr
+			if path := os.Getenv(TracesFileEnv); path != "" {
+				f, openErr := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+				if openErr != nil {
+					err = fmt.Errorf("failed to open traces file: %w", openErr)
+					break
+				}
+				closers = append(closers, f)
+				w = f
+			}
+			exporter, err = stdouttrace.New(stdouttrace.WithWriter(w))
+		default:
+			err = fmt.Errorf("unsupported OTEL_TRACES_EXPORTER %q (use otlp, console or none)", name)
+		}
+		if err != nil {
+			_ = shutdownExporters(ctx)
+			return noop, err
+		}
+		exporters = append(exporters, exporter)
+	}
+
+	if len(exporters) == 0 {
+		return noop, nil
+	}
+
+	// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES override the defaults
+	res, err := resource.New(ctx,
+		resource.WithAttributes(
+			semconv.ServiceName(serviceName),
+			semconv.ServiceVersion(version),
+		),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		_ = shutdownExporters(ctx)
+		return noop, fmt.Errorf("failed to build trace resource: %w", err)
+	}
+
+	// The sampler is configured with OTEL_TRACES_SAMPLER
+	opts := []sdktrace.TracerProviderOption{sdktrace.WithResource(res)}
+	for _, exporter := range exporters {
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	}
+	provider := sdktrace.NewTracerProvider(opts...)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return func(ctx context.Context) error {
+		// Shutting down the provider also shuts down its exporters
+		err := provider.Shutdown(ctx)
+		for _, closer := range closers {
+			err = errors.Join(err, closer.Close())
+		}
+		return err
+	}, nil
+}
+
+// newOTLPExporter creates an OTLP exporter for the protocol selected by
+// OTEL_EXPORTER_OTLP_TRACES_PROTOCOL or OTEL_EXPORTER_OTLP_PROTOCOL. The
+// endpoint, headers and TLS settings are read from the environment by the
+// exporter itself.
+func newOTLPExporter(ctx context.Context) (sdktrace.SpanExporter, error) {
+	protocol := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL")
+	if protocol == "" {
+		protocol = os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL")
+	}
+
+	switch protocol {
+	case "", "http/protobuf":
+		return otlptracehttp.New(ctx)
+	case "grpc":
+		return otlptracegrpc.New(ctx)
+	default:
+		return nil, fmt.Errorf("unsupported OTLP protocol %q (use grpc or http/protobuf)", protocol)
+	}
+}
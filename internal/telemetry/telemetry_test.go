@@ -0,0 +1,83 @@
+package telemetry
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+)
+
+func TestSetup_ConsoleFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces.json")
+	t.Setenv("OTEL_TRACES_EXPORTER", "console")
+	t.Setenv(TracesFileEnv, path)
+	t.Setenv("OTEL_SERVICE_NAME", "devin-test")
+
+	ctx := context.Background()
+	shutdown, err := Setup(ctx, "1.2.3")
+	if err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	_, span := otel.Tracer("test").Start(ctx, "test-span")
+	span.End()
+	if err := shutdown(ctx); err != nil {
+		t.Fatalf("shutdown() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	for _, want := range []string{`"Name":"test-span"`, `"devin-test"`, `"1.2.3"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("traces file does not contain %s:\n%s", want, data)
+		}
+	}
+}
+
+func TestSetup_Disabled(t *testing.T) {
+	tests := map[string]map[string]string{
+		"unset":        {"OTEL_TRACES_EXPORTER": ""},
+		"none":         {"OTEL_TRACES_EXPORTER": "none"},
+		"sdk disabled": {"OTEL_TRACES_EXPORTER": "console", "OTEL_SDK_DISABLED": "true"},
+	}
+	for name, env := range tests {
+		t.Run(name, func(t *testing.T) {
+			for k, v := range env {
+				t.Setenv(k, v)
+			}
+			before := otel.GetTracerProvider()
+
+			shutdown, err := Setup(context.Background(), "dev")
+			if err != nil {
+				t.Fatalf("Setup() error = %v", err)
+			}
+			if err := shutdown(context.Background()); err != nil {
+				t.Errorf("shutdown() error = %v", err)
+			}
+			if otel.GetTracerProvider() != before {
+				t.Error("Setup() should not install a tracer provider")
+			}
+		})
+	}
+}
+
+func TestSetup_InvalidSettings(t *testing.T) {
+	tests := map[string]map[string]string{
+		"exporter":      {"OTEL_TRACES_EXPORTER": "zipkin"},
+		"OTLP protocol": {"OTEL_TRACES_EXPORTER": "otlp", "OTEL_EXPORTER_OTLP_PROTOCOL": "http/json"},
+	}
+	for name, env := range tests {
+		t.Run(name, func(t *testing.T) {
+			for k, v := range env {
+				t.Setenv(k, v)
+			}
+			if _, err := Setup(context.Background(), "dev"); err == nil {
+				t.Error("Setup() should return an error")
+			}
+		})
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"flag"
 	"log"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
 	"github.com/hirosi1900day/terraform-provider-devin-knowledge/internal/provider"
+	"github.com/hirosi1900day/terraform-provider-devin-knowledge/internal/telemetry"
 )
 
 //go:generate go run github.com/hashicorp/terraform-plugin-docs/cmd/tfplugindocs generate --provider-name devin
@@ -27,7 +29,26 @@ func main() {
 		Debug:   debug,
 	}
 
-	err := providerserver.Serve(context.Background(), provider.New(version), opts)
+	// Tracing is optional; a bad OTEL_* setting must not stop the provider
+	shutdownTracing, err := telemetry.Setup(context.Background(), version)
+	if err != nil {
+		log.Printf("[WARN] OpenTelemetry tracing disabled: %s", err)
+	}
+
+	usage := provider.NewUsageReporter()
+	err = providerserver.Serve(context.Background(), provider.NewWithUsageReporter(version, usage), opts)
+
+	if reportErr := usage.Report(); reportErr != nil {
+		log.Printf("[WARN] Failed to write Devin API usage report: %s", reportErr)
+	}
+
+	// Every operation flushes its own spans; Terraform kills the provider
+	// soon after Serve returns, so only wait briefly for the rest
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	if shutdownErr := shutdownTracing(ctx); shutdownErr != nil {
+		log.Printf("[WARN] Failed to flush OpenTelemetry spans: %s", shutdownErr)
+	}
+	cancel()
 
 	if err != nil {
 		log.Fatal(err.Error())
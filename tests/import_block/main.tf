@@ -8,7 +8,7 @@ terraform {
 }
 
 provider "devin" {
-  api_key = "test_api_key" # In production, set this as an environment variable or Terraform Cloud variable
+  # The API key is read from the DEVIN_API_KEY environment variable or a Terraform Cloud variable
 }
 
 # Use an import block to import existing knowledge resource
@@ -16,7 +16,7 @@ provider "devin" {
 # Once the import is complete, you can comment out or remove this block
 import {
   to = devin_knowledge.imported_block
-  id = "mock-knowledge-1"
+  id = "mock-knowledge-1" # Replace with the ID of an existing knowledge resource
 }
 
 # Definition of the imported resource
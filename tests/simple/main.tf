@@ -8,8 +8,7 @@ terraform {
 }
 
 provider "devin" {
-  # Set test API key
-  api_key = "test_api_key"
+  # The API key is read from the DEVIN_API_KEY environment variable
 }
 
 # Create a knowledge resource